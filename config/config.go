@@ -3,12 +3,16 @@
 package config
 
 import (
+	"crypto"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type DBConfig struct {
@@ -25,16 +29,85 @@ type APIConfig struct {
 }
 
 type TokenConfig struct {
-	ApplicationName     string
-	JWTSignatureKey     []byte
-	JWTSigningMethod    *jwt.SigningMethodHMAC
-	AccessTokenLifeTime time.Duration
+	ApplicationName      string
+	JWTSignatureKey      []byte // secret untuk HS256
+	JWTSigningMethod     jwt.SigningMethod
+	JWTPrivateKey        crypto.PrivateKey // untuk RS256 / EdDSA
+	JWTKeyID             string
+	JWTVerificationKeys  map[string]crypto.PublicKey // kid => public key, termasuk key lama saat rotasi
+	AccessTokenLifeTime  time.Duration
+	RefreshTokenLifeTime time.Duration
+	ResetTokenLifeTime   time.Duration
+
+	EmailVerificationRequired bool   // false => user yang belum verifikasi tetap bisa login
+	EmailVerificationSecret   []byte // untuk tanda tangan link verifikasi email
+	VerifyTokenLifeTime       time.Duration
+
+	MFATokenLifeTime time.Duration // token sementara antara login password dan kode MFA
+	MFAEncryptionKey []byte        // untuk enkripsi secret TOTP di database
+
+	ImpersonationTokenLifeTime time.Duration // token admin saat login sebagai user lain
+}
+
+type PaymentConfig struct {
+	Gateway       string // fake, midtrans, stripe
+	FakeEnabled   bool   // gateway fake hanya untuk development, harus diaktifkan eksplisit
+	ServerKey     string
+	BaseURL       string
+	Currency      string
+	WebhookSecret []byte
+	RefundWindow  time.Duration
+}
+
+type MailConfig struct {
+	MailSender   string // log, file, smtp
+	MailFrom     string
+	MailFilePath string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	AppBaseURL   string // dipakai untuk link di email
+}
+
+type LoginConfig struct {
+	MaxLoginAttempts      int // gagal per akun sebelum dikunci
+	MaxLoginAttemptsPerIP int // gagal per IP sebelum dikunci
+	LoginLockoutBase      time.Duration
+	LoginLockoutMax       time.Duration
+}
+
+type OIDCConfig struct {
+	OIDCProvider      string // kosong => login OIDC nonaktif, stub, oidc
+	OIDCName          string // nama provider yang disimpan di user_identities, misalnya google atau keycloak
+	OIDCIssuerURL     string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCRedirectURL   string
+	OIDCScopes        string
+	OIDCStateLifeTime time.Duration
+	OIDCStubEnabled   bool // provider stub menerbitkan identitas untuk email apa pun, hanya untuk development
+}
+
+type PasswordConfig struct {
+	PasswordMinLength     int
+	PasswordRequireUpper  bool
+	PasswordRequireLower  bool
+	PasswordRequireDigit  bool
+	PasswordRequireSymbol bool
+	PasswordBreachedFile  string // direktori range HIBP atau file hash terurut, kosong => cek password bocor nonaktif
+	BcryptCost            int    // diubah => hash lama di-rehash saat user login
 }
 
 type Config struct {
 	DBConfig
 	APIConfig
 	TokenConfig
+	PaymentConfig
+	MailConfig
+	LoginConfig
+	OIDCConfig
+	PasswordConfig
 }
 
 func (c *Config) readConfig() error {
@@ -57,12 +130,142 @@ func (c *Config) readConfig() error {
 	}
 
 	c.TokenConfig = TokenConfig{
-		ApplicationName:     os.Getenv("APPLICATION_NAME"),   
-		JWTSignatureKey:     []byte(os.Getenv("JWT_SECRET")), 
+		ApplicationName:     os.Getenv("APPLICATION_NAME"),
+		JWTSignatureKey:     []byte(os.Getenv("JWT_SECRET")),
 		JWTSigningMethod:    jwt.SigningMethodHS256,
 		AccessTokenLifeTime: time.Duration(1) * time.Hour, // 1 jam
 	}
 
+	err = c.readSigningKeys()
+	if err != nil {
+		return err
+	}
+
+	refreshTokenDays, err := strconv.Atoi(os.Getenv("REFRESH_TOKEN_LIFETIME_DAYS"))
+	if err != nil || refreshTokenDays <= 0 {
+		refreshTokenDays = 30
+	}
+	c.RefreshTokenLifeTime = time.Duration(refreshTokenDays) * 24 * time.Hour
+	c.ResetTokenLifeTime = time.Duration(30) * time.Minute // 30 menit
+
+	// Default wajib verifikasi, bisa dimatikan untuk development
+	c.EmailVerificationRequired = os.Getenv("EMAIL_VERIFICATION_REQUIRED") != "false"
+	c.EmailVerificationSecret = []byte(os.Getenv("EMAIL_VERIFICATION_SECRET"))
+	if len(c.EmailVerificationSecret) == 0 {
+		c.EmailVerificationSecret = c.JWTSignatureKey
+	}
+	if len(c.EmailVerificationSecret) == 0 {
+		return fmt.Errorf("EMAIL_VERIFICATION_SECRET is required")
+	}
+	c.VerifyTokenLifeTime = time.Duration(24) * time.Hour // 24 jam
+
+	c.MFATokenLifeTime = time.Duration(5) * time.Minute // 5 menit
+	c.MFAEncryptionKey = []byte(os.Getenv("MFA_ENCRYPTION_KEY"))
+	if len(c.MFAEncryptionKey) == 0 {
+		c.MFAEncryptionKey = c.JWTSignatureKey
+	}
+	if len(c.MFAEncryptionKey) == 0 {
+		return fmt.Errorf("MFA_ENCRYPTION_KEY is required")
+	}
+
+	c.ImpersonationTokenLifeTime = time.Duration(envInt("IMPERSONATION_TOKEN_MINUTES", 15)) * time.Minute
+
+	c.PaymentConfig = PaymentConfig{
+		Gateway:       os.Getenv("PAYMENT_GATEWAY"),
+		ServerKey:     os.Getenv("PAYMENT_SERVER_KEY"),
+		BaseURL:       os.Getenv("PAYMENT_BASE_URL"),
+		Currency:      os.Getenv("PAYMENT_CURRENCY"),
+		WebhookSecret: []byte(os.Getenv("PAYMENT_WEBHOOK_SECRET")),
+		FakeEnabled:   os.Getenv("PAYMENT_FAKE_ENABLED") == "true",
+	}
+	// Tidak ada default, gateway yang salah set bisa membuat kursus berbayar gratis
+	if c.Gateway == "" {
+		return fmt.Errorf("PAYMENT_GATEWAY is required")
+	}
+	if c.Gateway == "fake" && !c.FakeEnabled {
+		return fmt.Errorf("PAYMENT_GATEWAY=fake is for development only, set PAYMENT_FAKE_ENABLED=true to use it")
+	}
+	if c.Currency == "" {
+		c.Currency = "IDR"
+	}
+
+	refundWindowDays, err := strconv.Atoi(os.Getenv("REFUND_WINDOW_DAYS"))
+	if err != nil || refundWindowDays <= 0 {
+		refundWindowDays = 14
+	}
+	c.RefundWindow = time.Duration(refundWindowDays) * 24 * time.Hour
+
+	c.MailConfig = MailConfig{
+		MailSender:   os.Getenv("MAIL_SENDER"),
+		MailFrom:     os.Getenv("MAIL_FROM"),
+		MailFilePath: os.Getenv("MAIL_FILE_PATH"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		AppBaseURL:   os.Getenv("APP_BASE_URL"),
+	}
+	if c.MailSender == "" {
+		c.MailSender = "log"
+	}
+	if c.MailFrom == "" {
+		c.MailFrom = "no-reply@edulearn.local"
+	}
+	if c.MailFilePath == "" {
+		c.MailFilePath = "mail.log"
+	}
+	if c.AppBaseURL == "" {
+		c.AppBaseURL = "http://localhost:8080"
+	}
+
+	c.LoginConfig = LoginConfig{
+		MaxLoginAttempts:      envInt("LOGIN_MAX_ATTEMPTS", 5),
+		MaxLoginAttemptsPerIP: envInt("LOGIN_MAX_ATTEMPTS_PER_IP", 20),
+		LoginLockoutBase:      time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", 1)) * time.Minute,
+		LoginLockoutMax:       time.Duration(envInt("LOGIN_LOCKOUT_MAX_MINUTES", 60)) * time.Minute,
+	}
+
+	c.OIDCConfig = OIDCConfig{
+		OIDCProvider:      os.Getenv("OIDC_PROVIDER"),
+		OIDCName:          os.Getenv("OIDC_NAME"),
+		OIDCIssuerURL:     strings.TrimSuffix(os.Getenv("OIDC_ISSUER_URL"), "/"),
+		OIDCClientID:      os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:   os.Getenv("OIDC_REDIRECT_URL"),
+		OIDCScopes:        os.Getenv("OIDC_SCOPES"),
+		OIDCStateLifeTime: time.Duration(10) * time.Minute, // 10 menit
+		OIDCStubEnabled:   os.Getenv("OIDC_STUB_ENABLED") == "true",
+	}
+	if c.OIDCName == "" {
+		c.OIDCName = c.OIDCProvider
+	}
+	if c.OIDCRedirectURL == "" {
+		c.OIDCRedirectURL = c.AppBaseURL + "/api/oidc/callback"
+	}
+	if c.OIDCScopes == "" {
+		c.OIDCScopes = "openid email profile"
+	}
+	// Stub bisa login sebagai user mana pun, tidak boleh aktif hanya karena OIDC_PROVIDER salah set
+	if c.OIDCProvider == "stub" && !c.OIDCStubEnabled {
+		return fmt.Errorf("OIDC_PROVIDER=stub is for development only, set OIDC_STUB_ENABLED=true to use it")
+	}
+	if c.OIDCProvider == "oidc" && (c.OIDCIssuerURL == "" || c.OIDCClientID == "") {
+		return fmt.Errorf("OIDC_ISSUER_URL and OIDC_CLIENT_ID are required")
+	}
+
+	c.PasswordConfig = PasswordConfig{
+		PasswordMinLength:     envInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequireUpper:  os.Getenv("PASSWORD_REQUIRE_UPPER") != "false",
+		PasswordRequireLower:  os.Getenv("PASSWORD_REQUIRE_LOWER") != "false",
+		PasswordRequireDigit:  os.Getenv("PASSWORD_REQUIRE_DIGIT") != "false",
+		PasswordRequireSymbol: os.Getenv("PASSWORD_REQUIRE_SYMBOL") == "true",
+		PasswordBreachedFile:  os.Getenv("PASSWORD_BREACHED_FILE"),
+		BcryptCost:            envInt("BCRYPT_COST", bcrypt.DefaultCost),
+	}
+	if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
 	if c.Host == "" || c.Port == "" || c.Username == "" || c.Password == "" || c.ApiPort == "" {
 		return fmt.Errorf("required config")
 	}
@@ -80,3 +283,80 @@ func NewConfig() (*Config, error) {
 
 	return cfg, nil
 }
+
+// envInt => Baca angka positif dari env, pakai fallback jika kosong atau tidak valid
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// readSigningKeys => Baca key RS256/EdDSA dari file PEM, default tetap HS256 dengan JWT_SECRET
+func (c *Config) readSigningKeys() error {
+	algorithm := os.Getenv("JWT_ALGORITHM")
+	if algorithm == "" || algorithm == jwt.SigningMethodHS256.Alg() {
+		return nil
+	}
+
+	var parsePrivate func([]byte) (crypto.PrivateKey, error)
+	var parsePublic func([]byte) (crypto.PublicKey, error)
+
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		c.JWTSigningMethod = jwt.SigningMethodRS256
+		parsePrivate = func(b []byte) (crypto.PrivateKey, error) { return jwt.ParseRSAPrivateKeyFromPEM(b) }
+		parsePublic = func(b []byte) (crypto.PublicKey, error) { return jwt.ParseRSAPublicKeyFromPEM(b) }
+	case jwt.SigningMethodEdDSA.Alg():
+		c.JWTSigningMethod = jwt.SigningMethodEdDSA
+		parsePrivate = jwt.ParseEdPrivateKeyFromPEM
+		parsePublic = jwt.ParseEdPublicKeyFromPEM
+	default:
+		return fmt.Errorf("unsupported JWT_ALGORITHM: %s", algorithm)
+	}
+
+	c.JWTKeyID = os.Getenv("JWT_KEY_ID")
+	if c.JWTKeyID == "" {
+		return fmt.Errorf("JWT_KEY_ID is required for %s", algorithm)
+	}
+
+	privatePEM, err := os.ReadFile(os.Getenv("JWT_PRIVATE_KEY_FILE"))
+	if err != nil {
+		return fmt.Errorf("failed to read JWT_PRIVATE_KEY_FILE: %v", err)
+	}
+	c.JWTPrivateKey, err = parsePrivate(privatePEM)
+	if err != nil {
+		return fmt.Errorf("invalid JWT private key: %v", err)
+	}
+
+	signer, ok := c.JWTPrivateKey.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("invalid JWT private key type")
+	}
+	c.JWTVerificationKeys = map[string]crypto.PublicKey{c.JWTKeyID: signer.Public()}
+
+	// Key lama yang masih dipakai untuk verifikasi, format: kid1=path1,kid2=path2
+	for _, entry := range strings.Split(os.Getenv("JWT_PUBLIC_KEY_FILES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, path, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid JWT_PUBLIC_KEY_FILES entry: %s", entry)
+		}
+
+		publicPEM, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read public key %s: %v", kid, err)
+		}
+		c.JWTVerificationKeys[kid], err = parsePublic(publicPEM)
+		if err != nil {
+			return fmt.Errorf("invalid public key %s: %v", kid, err)
+		}
+	}
+
+	return nil
+}
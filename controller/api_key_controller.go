@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"edu-learn/middleware"
+	"edu-learn/model"
+	"edu-learn/model/dto"
+	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type apiKeyController struct {
+	useCase        usecase.APIKeyUseCase
+	rg             *gin.RouterGroup
+	authMiddleware middleware.AuthMiddleware
+}
+
+func (a *apiKeyController) Route() {
+	// Token impersonation tidak boleh membuat key yang bisa dipakai setelah sesi berakhir
+	keyRoutes := a.rg.Group("/api-keys", a.authMiddleware.RequirePermission(policy.APIKeyManage), a.authMiddleware.BlockImpersonation())
+	{
+		keyRoutes.POST("", a.createOwnAPIKey)
+		keyRoutes.GET("", a.getOwnAPIKeys)
+		keyRoutes.DELETE("/:id", a.revokeAPIKey)
+	}
+
+	serviceAccountRoutes := a.rg.Group("/service-accounts", a.authMiddleware.RequirePermission(policy.ServiceAccountManage), a.authMiddleware.BlockImpersonation())
+	{
+		serviceAccountRoutes.POST("", a.createServiceAccount)
+		serviceAccountRoutes.GET("", a.getServiceAccounts)
+		serviceAccountRoutes.POST("/:id/api-keys", a.createServiceAccountAPIKey)
+		serviceAccountRoutes.GET("/:id/api-keys", a.getServiceAccountAPIKeys)
+	}
+}
+
+func (a *apiKeyController) createOwnAPIKey(c *gin.Context) {
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	a.createAPIKey(c, actor.ID, actor)
+}
+
+func (a *apiKeyController) createServiceAccountAPIKey(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	a.createAPIKey(c, userID, actor)
+}
+
+func (a *apiKeyController) createAPIKey(c *gin.Context, userID int, actor model.User) {
+	var payload dto.CreateAPIKeyDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	key, rawKey, err := a.useCase.CreateAPIKey(userID, &model.APIKey{Name: payload.Name, Scopes: payload.Scopes}, payload.ExpiresInDays, actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "API key created, store it somewhere safe", dto.APIKeyCreatedResponse{APIKey: key, Key: rawKey})
+}
+
+func (a *apiKeyController) getOwnAPIKeys(c *gin.Context) {
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	a.getAPIKeys(c, actor.ID, actor)
+}
+
+func (a *apiKeyController) getServiceAccountAPIKeys(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	a.getAPIKeys(c, userID, actor)
+}
+
+func (a *apiKeyController) getAPIKeys(c *gin.Context, userID int, actor model.User) {
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	keys, total, err := a.useCase.GetAPIKeys(userID, page, actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, "API keys retrieved successfully", keys, page, total)
+}
+
+func (a *apiKeyController) revokeAPIKey(c *gin.Context) {
+	keyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid api key id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = a.useCase.RevokeAPIKey(keyID, actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "API key revoked successfully", nil)
+}
+
+func (a *apiKeyController) createServiceAccount(c *gin.Context) {
+	var payload dto.CreateServiceAccountDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	account, err := a.useCase.CreateServiceAccount(&model.User{Name: payload.Name, Role: payload.Role}, actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Service account created successfully", dto.ToUserResponse(account))
+}
+
+func (a *apiKeyController) getServiceAccounts(c *gin.Context) {
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	accounts, total, err := a.useCase.GetServiceAccounts(page, actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, "Service accounts retrieved successfully", dto.ToUserResponses(accounts), page, total)
+}
+
+func NewAPIKeyController(useCase usecase.APIKeyUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *apiKeyController {
+	return &apiKeyController{useCase: useCase, rg: rg, authMiddleware: authMiddleware}
+}
@@ -1,70 +1,217 @@
 package controller
 
 import (
-	"edu-learn/model"
+	"edu-learn/middleware"
+	"edu-learn/model/dto"
 	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 type authController struct {
-	authUC usecase.AuthenticationUseCase
-	rg     *gin.RouterGroup
+	authUC         usecase.AuthenticationUseCase
+	rg             *gin.RouterGroup
+	authMiddleware middleware.AuthMiddleware
 }
 
 func (a *authController) Route() {
 	a.rg.POST("/register", a.registerController)
 	a.rg.POST("/login", a.loginController)
+	a.rg.POST("/login/mfa", a.loginMFAController)
+	a.rg.POST("/login/mfa/enroll", a.enrollMFAController)
+	a.rg.POST("/login/mfa/enroll/confirm", a.confirmMFAController)
+	a.rg.POST("/refresh", a.refreshController)
+	a.rg.POST("/logout", a.authMiddleware.RequireToken("admin", "student", "instructor"), a.logoutController)
+
+	a.rg.POST("/users/:id/unlock", a.authMiddleware.RequirePermission(policy.UserUnlock), a.unlockAccountController)
+	a.rg.GET("/users/:id/login-history", a.authMiddleware.RequirePermission(policy.UserRead), a.loginHistoryController)
 }
 
 func (a *authController) registerController(c *gin.Context) {
-	var payload model.User
+	var payload dto.RegisterDto
 
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &payload) {
 		return
 	}
 
-	user, err := a.authUC.RegisterUseCase(&payload)
+	newUser := payload.ToModel()
+	user, err := a.authUC.RegisterUseCase(&newUser)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, struct {
-		Message string     `json:"message"`
-		Data    model.User `json:"data"`
-	}{
-		Message: "Login Success",
-		Data:    user,
-	})
+	response.Success(c, http.StatusCreated, "User registered successfully", dto.ToUserResponse(user))
 }
 
 func (a *authController) loginController(c *gin.Context) {
-	var payload model.User
+	var payload dto.LoginDto
 
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &payload) {
 		return
 	}
 
 	// Dapat diubah jika user login dengan email
-	token, err := a.authUC.LoginUseCase(payload.Email, payload.Password)
+	result, err := a.authUC.LoginUseCase(payload.Email, payload.Password, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	writeLoginResult(c, result)
+}
+
+// writeLoginResult => Dipakai login password dan callback OIDC
+func writeLoginResult(c *gin.Context, result modelutils.LoginResult) {
+	// Kredensial benar, lanjut ke POST /login/mfa (atau /login/mfa/enroll jika wajib mendaftar)
+	if result.MFA != nil {
+		response.Success(c, http.StatusOK, "MFA verification required", *result.MFA)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Login Success", result.Tokens)
+}
+
+func (a *authController) loginMFAController(c *gin.Context) {
+	var payload dto.LoginMFADto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	tokens, err := a.authUC.LoginMFAUseCase(payload.MFAToken, payload.Code, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Login Success", tokens)
+}
+
+func (a *authController) enrollMFAController(c *gin.Context) {
+	var payload dto.MFATokenDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	enrollment, err := a.authUC.EnrollMFAUseCase(payload.MFAToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Scan the provisioning URI with your authenticator app", enrollment)
+}
+
+func (a *authController) confirmMFAController(c *gin.Context) {
+	var payload dto.LoginMFADto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	tokens, recoveryCodes, err := a.authUC.ConfirmMFAUseCase(payload.MFAToken, payload.Code, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "MFA enabled, store the recovery codes somewhere safe", dto.MFAConfirmResponse{TokenPair: tokens, RecoveryCodes: recoveryCodes})
+}
+
+func (a *authController) refreshController(c *gin.Context) {
+	var payload dto.RefreshTokenDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	tokens, err := a.authUC.RefreshUseCase(payload.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Token refreshed successfully", tokens)
+}
+
+func (a *authController) logoutController(c *gin.Context) {
+	// Body opsional: { "refresh_token": "..." }
+	var payload dto.LogoutDto
+	c.ShouldBindJSON(&payload)
+
+	claims, err := middleware.ExtractClaims(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = a.authUC.LogoutUseCase(claims, payload.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Logout Success", nil)
+}
+
+func (a *authController) unlockAccountController(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	err = a.authUC.UnlockAccount(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Account unlocked successfully", nil)
+}
+
+func (a *authController) loginHistoryController(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Selain admin hanya bisa melihat riwayat login sendiri
+	err = policy.Authorize(actor, policy.UserRead, actor.ID == userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	histories, total, err := a.authUC.GetLoginHistory(userID, page)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, struct {
-		Message string `json:"message"`
-		Token   string `json:"token"`
-	}{
-		Message: "Login Success",
-		Token:   token,
-	})
+	response.Paginated(c, "Login history retrieved successfully", histories, page, total)
 }
 
-func NewAuthController(authUc usecase.AuthenticationUseCase, rg *gin.RouterGroup) *authController {
-	return &authController{authUC: authUc, rg: rg}
+func NewAuthController(authUc usecase.AuthenticationUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *authController {
+	return &authController{authUC: authUc, rg: rg, authMiddleware: authMiddleware}
 }
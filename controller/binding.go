@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"edu-learn/model/dto"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindJSON => Body rusak atau field tidak dikenal dibalas 400, field yang gagal validasi dibalas 422
+func bindJSON(c *gin.Context, payload interface{}) bool {
+	return bindError(c, c.ShouldBindJSON(payload))
+}
+
+// bindPage => Baca ?page=&per_page=, nilai kosong diganti default
+func bindPage(c *gin.Context) (modelutils.PageQuery, bool) {
+	var query dto.PageDto
+	if !bindError(c, c.ShouldBindQuery(&query)) {
+		return modelutils.PageQuery{}, false
+	}
+
+	return query.ToPageQuery(), true
+}
+
+func bindError(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	fields, ok := validation.FieldErrors(err)
+	if ok {
+		c.Error(apperror.InvalidFields(fields))
+	} else {
+		c.Error(apperror.BadRequest("%s", err.Error()))
+	}
+
+	return false
+}
@@ -2,8 +2,11 @@ package controller
 
 import (
 	"edu-learn/middleware"
-	"edu-learn/model"
+	"edu-learn/model/dto"
 	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
 	"net/http"
 	"strconv"
 
@@ -11,158 +14,156 @@ import (
 )
 
 type courseController struct {
-	useCase        usecase.CourseUseCase
-	rg             *gin.RouterGroup
-	authMiddleware middleware.AuthMiddleware
+	useCase               usecase.CourseUseCase
+	rg                    *gin.RouterGroup
+	authMiddleware        middleware.AuthMiddleware
+	idempotencyMiddleware middleware.IdempotencyMiddleware
 }
 
 func (c *courseController) Route() {
 	// Public
 	c.rg.GET("/courses", c.getAllCourses)
+	c.rg.GET("/courses/search", c.searchCourses)
 	c.rg.GET("/courses/:id", c.getCourseById)
 
-	instructorRoutes := c.rg.Group("/courses", c.authMiddleware.RequireToken("instructor"))
-	{
-		instructorRoutes.POST("/", c.createCourse)
-		instructorRoutes.PUT("/:id", c.updateCourse)
-		instructorRoutes.DELETE("/:id", c.deleteCourse)
-	}
+	// Instruktur hanya bisa mengubah kursus miliknya, dicek di usecase
+	c.rg.POST("/courses/", c.authMiddleware.RequirePermission(policy.CourseCreate), c.idempotencyMiddleware.Handle(), c.createCourse)
+	c.rg.PUT("/courses/:id", c.authMiddleware.RequirePermission(policy.CourseUpdate), c.updateCourse)
+	c.rg.DELETE("/courses/:id", c.authMiddleware.RequirePermission(policy.CourseDelete), c.deleteCourse)
 
-	c.rg.GET("/users/:id/courses", c.authMiddleware.RequireToken("student"), c.getCoursesByUserID)
+	c.rg.GET("/users/:id/courses", c.authMiddleware.RequirePermission(policy.UserRead), c.getCoursesByUserID)
 }
 
 func (c *courseController) getAllCourses(ctx *gin.Context) {
-	course, err := c.useCase.GetAllCourse()
+	var query dto.CourseQueryDto
+	if !bindError(ctx, ctx.ShouldBindQuery(&query)) {
+		return
+	}
+
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	course, total, err := c.useCase.GetAllCourse(query.ToFilter(), page)
 	if err != nil {
-		if err.Error() == "no courses found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "No courses found"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, struct {
-		Message string         `json:"message"`
-		Data    []model.Course `json:"data"`
-	}{
-		Message: "Course data retrieved successfully",
-		Data:    course,
-	})
+	response.Paginated(ctx, "Course data retrieved successfully", dto.ToCourseResponses(course), page, total)
+}
+
+func (c *courseController) searchCourses(ctx *gin.Context) {
+	var query dto.CourseSearchDto
+	if !bindError(ctx, ctx.ShouldBindQuery(&query)) {
+		return
+	}
+
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	results, total, err := c.useCase.SearchCourses(query.Query, page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Paginated(ctx, "Course search results retrieved successfully", dto.ToCourseSearchResponses(results), page, total)
 }
 
 func (c *courseController) createCourse(ctx *gin.Context) {
-	var payload model.Course
+	var payload dto.CourseDto
 
-	err := ctx.ShouldBindJSON(&payload)
+	if !bindJSON(ctx, &payload) {
+		return
+	}
+
+	actor, err := middleware.ExtractUser(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	course, err := c.useCase.CreateCourse(&payload)
+	newCourse := payload.ToModel()
+	course, err := c.useCase.CreateCourse(&newCourse, actor)
 	if err != nil {
-		if err.Error() == "instructor not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Instructor not found"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, struct {
-		Message string       `json:"message"`
-		Data    model.Course `json:"data"`
-	}{
-		Message: "Course create successfully",
-		Data:    course,
-	})
+	response.Success(ctx, http.StatusCreated, "Course created successfully", dto.ToCourseResponse(course))
 }
 
 func (c *courseController) getCourseById(ctx *gin.Context) {
 	id := ctx.Param("id")
 	courseId, err := strconv.Atoi(id)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course id"})
+		ctx.Error(apperror.BadRequest("invalid course id"))
 		return
 	}
 
 	course, err := c.useCase.GetCourseById(courseId)
 	if err != nil {
-		if err.Error() == "invalid course id" {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course id"})
-		} else if err.Error() == "course not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-		return
-	}
-
-	ctx.JSON(http.StatusOK, struct {
-		Message string       `json:"message"`
-		Data    model.Course `json:"data"`
-	}{
-		Message: "Course data retrieved successfully",
-		Data:    course,
-	})
+		ctx.Error(err)
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, "Course data retrieved successfully", dto.ToCourseResponse(course))
 }
 
 func (c *courseController) updateCourse(ctx *gin.Context) {
 	id := ctx.Param("id")
 	courseId, err := strconv.Atoi(id)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course id"})
+		ctx.Error(apperror.BadRequest("invalid course id"))
+		return
+	}
+
+	var payload dto.CourseDto
+	if !bindJSON(ctx, &payload) {
 		return
 	}
 
-	var payload model.Course
-	err = ctx.ShouldBindJSON(&payload)
+	actor, err := middleware.ExtractUser(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	course, err := c.useCase.UpdateCourse(courseId, &payload)
+	updatedCourse := payload.ToModel()
+	course, err := c.useCase.UpdateCourse(courseId, &updatedCourse, actor)
 	if err != nil {
-		if err.Error() == "course not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		} else if err.Error() == "instructor not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Instructor not found"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-		return
-	}
-
-	ctx.JSON(http.StatusOK, struct {
-		Message string       `json:"message"`
-		Data    model.Course `json:"data"`
-	}{
-		Message: "Course updated successfully",
-		Data:    course,
-	})
+		ctx.Error(err)
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, "Course updated successfully", dto.ToCourseResponse(course))
 }
 
 func (c *courseController) deleteCourse(ctx *gin.Context) {
 	id := ctx.Param("id")
 	courseId, err := strconv.Atoi(id)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course id"})
+		ctx.Error(apperror.BadRequest("invalid course id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(ctx)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	err = c.useCase.DeleteCourse(courseId)
+	err = c.useCase.DeleteCourse(courseId, actor)
 	if err != nil {
-		if err.Error() == "course not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Course deleted successfully"})
+	response.Success(ctx, http.StatusOK, "Course deleted successfully", nil)
 }
 
 func (c *courseController) getCoursesByUserID(ctx *gin.Context) {
@@ -170,31 +171,30 @@ func (c *courseController) getCoursesByUserID(ctx *gin.Context) {
 
 	userID, err := strconv.Atoi(idParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"message": "Invalid user ID"})
+		ctx.Error(apperror.BadRequest("invalid user id"))
 		return
 	}
 
-	courses, err := c.useCase.GetCoursesByUserID(userID)
+	actor, err := middleware.ExtractUser(ctx)
 	if err != nil {
-		if err.Error() == "no courses found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "No courses found"})
-		} else if err.Error() == "forbidden: only students can access their courses" {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-		return
-	}
-
-	ctx.JSON(http.StatusOK, struct {
-		Message string         `json:"message"`
-		Data    []model.Course `json:"data"`
-	}{
-		Message: "Course data retrieved successfully",
-		Data:    courses,
-	})
+		ctx.Error(err)
+		return
+	}
+
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	courses, total, err := c.useCase.GetCoursesByUserID(userID, page, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Paginated(ctx, "Course data retrieved successfully", dto.ToCourseResponses(courses), page, total)
 }
 
-func NewCourseController(useCase usecase.CourseUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *courseController {
-	return &courseController{useCase: useCase, rg: rg, authMiddleware: authMiddleware}
+func NewCourseController(useCase usecase.CourseUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware, idempotencyMiddleware middleware.IdempotencyMiddleware) *courseController {
+	return &courseController{useCase: useCase, rg: rg, authMiddleware: authMiddleware, idempotencyMiddleware: idempotencyMiddleware}
 }
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"edu-learn/model/dto"
+	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type emailVerificationController struct {
+	useCase usecase.EmailVerificationUseCase
+	rg      *gin.RouterGroup
+}
+
+func (e *emailVerificationController) Route() {
+	// GET karena dibuka langsung dari link di email
+	e.rg.GET("/email/verify", e.verifyEmail)
+	e.rg.POST("/email/verify/resend", e.resendVerification)
+}
+
+func (e *emailVerificationController) verifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.Error(apperror.BadRequest("verification token is required"))
+		return
+	}
+
+	err := e.useCase.VerifyEmail(token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Email verified successfully", nil)
+}
+
+func (e *emailVerificationController) resendVerification(c *gin.Context) {
+	var payload dto.ResendVerificationDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	err := e.useCase.ResendVerificationEmail(payload.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Response sama untuk email terdaftar maupun tidak
+	response.Success(c, http.StatusOK, "If the email is registered and not yet verified, a verification link has been sent", nil)
+}
+
+func NewEmailVerificationController(useCase usecase.EmailVerificationUseCase, rg *gin.RouterGroup) *emailVerificationController {
+	return &emailVerificationController{useCase: useCase, rg: rg}
+}
@@ -2,7 +2,11 @@ package controller
 
 import (
 	"edu-learn/middleware"
+	"edu-learn/model/dto"
 	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
 	"net/http"
 	"strconv"
 
@@ -10,38 +14,39 @@ import (
 )
 
 type enrollmentController struct {
-	useCase        usecase.EnrollmentUseCase
-	rg             *gin.RouterGroup
-	authMiddleware middleware.AuthMiddleware
+	useCase               usecase.EnrollmentUseCase
+	rg                    *gin.RouterGroup
+	authMiddleware        middleware.AuthMiddleware
+	idempotencyMiddleware middleware.IdempotencyMiddleware
 }
 
 func (c *enrollmentController) Route() {
-	c.rg.POST("/courses/:id/enroll", c.authMiddleware.RequireToken("student"), c.enrollCourse)
+	c.rg.POST("/courses/:id/enroll", c.authMiddleware.RequirePermission(policy.EnrollmentCreate), c.idempotencyMiddleware.Handle(), c.enrollCourse)
 }
 
 func (h *enrollmentController) enrollCourse(c *gin.Context) {
 	courseIDStr := c.Param("id")
 	courseID, err := strconv.Atoi(courseIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course ID"})
+		c.Error(apperror.BadRequest("invalid course id"))
 		return
 	}
 
 	userID, err := middleware.ExtractUserID(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"message": "Unauthorized"})
+		c.Error(err)
 		return
 	}
 
-	err = h.useCase.EnrollCourse(userID, courseID)
+	enrollment, err := h.useCase.EnrollCourse(userID, courseID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Successfully enrolled in course"})
+	response.Success(c, http.StatusOK, "Successfully enrolled in course", dto.ToEnrollmentResponse(enrollment))
 }
 
-func NewEnrollmentController(useCase usecase.EnrollmentUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *enrollmentController {
-	return &enrollmentController{useCase: useCase, rg: rg, authMiddleware: authMiddleware}
+func NewEnrollmentController(useCase usecase.EnrollmentUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware, idempotencyMiddleware middleware.IdempotencyMiddleware) *enrollmentController {
+	return &enrollmentController{useCase: useCase, rg: rg, authMiddleware: authMiddleware, idempotencyMiddleware: idempotencyMiddleware}
 }
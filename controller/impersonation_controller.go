@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"edu-learn/middleware"
+	"edu-learn/model/dto"
+	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type impersonationController struct {
+	useCase        usecase.ImpersonationUseCase
+	rg             *gin.RouterGroup
+	authMiddleware middleware.AuthMiddleware
+}
+
+func (i *impersonationController) Route() {
+	i.rg.POST("/users/:id/impersonate", i.authMiddleware.RequirePermission(policy.UserImpersonate), i.impersonate)
+	i.rg.GET("/impersonation/audit-logs", i.authMiddleware.RequirePermission(policy.AuditLogRead), i.getAuditLogs)
+}
+
+func (i *impersonationController) impersonate(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	var payload dto.ImpersonateDto
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	token, err := i.useCase.Impersonate(userID, payload.Reason, actor, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Impersonation started, every request with this token is audited", token)
+}
+
+func (i *impersonationController) getAuditLogs(c *gin.Context) {
+	// Filter opsional: ?impersonator_id=&user_id=
+	impersonatorID, err := strconv.Atoi(c.DefaultQuery("impersonator_id", "0"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid impersonator id"))
+		return
+	}
+	userID, err := strconv.Atoi(c.DefaultQuery("user_id", "0"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	logs, total, err := i.useCase.GetAuditLogs(impersonatorID, userID, page, actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, "Audit logs retrieved successfully", logs, page, total)
+}
+
+func NewImpersonationController(useCase usecase.ImpersonationUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *impersonationController {
+	return &impersonationController{useCase: useCase, rg: rg, authMiddleware: authMiddleware}
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"edu-learn/middleware"
+	"edu-learn/model"
+	"edu-learn/model/dto"
+	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type instructorApplicationController struct {
+	useCase        usecase.InstructorApplicationUseCase
+	rg             *gin.RouterGroup
+	authMiddleware middleware.AuthMiddleware
+}
+
+func (i *instructorApplicationController) Route() {
+	applicationRoutes := i.rg.Group("/instructor-applications")
+	{
+		applicationRoutes.POST("", i.authMiddleware.RequirePermission(policy.ApplicationCreate), i.apply)
+		applicationRoutes.GET("", i.authMiddleware.RequirePermission(policy.ApplicationRead), i.getApplications)
+		applicationRoutes.GET("/:id", i.authMiddleware.RequirePermission(policy.ApplicationRead), i.getApplicationById)
+		applicationRoutes.POST("/:id/approve", i.authMiddleware.RequirePermission(policy.ApplicationReview), i.approve)
+		applicationRoutes.POST("/:id/reject", i.authMiddleware.RequirePermission(policy.ApplicationReview), i.reject)
+	}
+}
+
+func (i *instructorApplicationController) apply(ctx *gin.Context) {
+	var payload dto.InstructorApplicationDto
+
+	if !bindJSON(ctx, &payload) {
+		return
+	}
+
+	actor, err := middleware.ExtractUser(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	application, err := i.useCase.Apply(&model.InstructorApplication{Bio: payload.Bio, Credentials: payload.Credentials}, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Success(ctx, http.StatusCreated, "Application submitted successfully", application)
+}
+
+func (i *instructorApplicationController) getApplications(ctx *gin.Context) {
+	actor, err := middleware.ExtractUser(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	applications, total, err := i.useCase.GetApplications(ctx.Query("status"), page, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Paginated(ctx, "Application data retrieved successfully", applications, page, total)
+}
+
+func (i *instructorApplicationController) getApplicationById(ctx *gin.Context) {
+	applicationId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.BadRequest("invalid application id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	application, err := i.useCase.GetApplicationById(applicationId, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, "Application data retrieved successfully", application)
+}
+
+func (i *instructorApplicationController) approve(ctx *gin.Context) {
+	i.review(ctx, true)
+}
+
+func (i *instructorApplicationController) reject(ctx *gin.Context) {
+	i.review(ctx, false)
+}
+
+func (i *instructorApplicationController) review(ctx *gin.Context, approve bool) {
+	applicationId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.BadRequest("invalid application id"))
+		return
+	}
+
+	// Catatan opsional, body boleh kosong
+	var payload dto.ReviewApplicationDto
+	ctx.ShouldBindJSON(&payload)
+
+	actor, err := middleware.ExtractUser(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	application, err := i.useCase.ReviewApplication(applicationId, approve, payload.Notes, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	message := "Application rejected"
+	if approve {
+		message = "Application approved, user promoted to instructor"
+	}
+
+	response.Success(ctx, http.StatusOK, message, application)
+}
+
+func NewInstructorApplicationController(useCase usecase.InstructorApplicationUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *instructorApplicationController {
+	return &instructorApplicationController{useCase: useCase, rg: rg, authMiddleware: authMiddleware}
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"edu-learn/utils/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type jwksController struct {
+	jwtService service.JwtService
+	rg         *gin.RouterGroup
+}
+
+func (j *jwksController) Route() {
+	// Public, dipakai service lain untuk memverifikasi token EduLearn
+	j.rg.GET("/.well-known/jwks.json", j.getJwks)
+}
+
+func (j *jwksController) getJwks(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, j.jwtService.JWKS())
+}
+
+func NewJwksController(jwtService service.JwtService, rg *gin.RouterGroup) *jwksController {
+	return &jwksController{jwtService: jwtService, rg: rg}
+}
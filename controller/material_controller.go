@@ -2,8 +2,11 @@ package controller
 
 import (
 	"edu-learn/middleware"
-	"edu-learn/model"
+	"edu-learn/model/dto"
 	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
 	"net/http"
 	"strconv"
 
@@ -17,16 +20,14 @@ type materialController struct {
 }
 
 func (m *materialController) Route() {
-	instructorRoutes := m.rg.Group("/courses/:id", m.authMiddleware.RequireToken("instructor"))
-	{	
-		instructorRoutes.POST("/materials", m.createMaterial)
-		instructorRoutes.PUT("/materials/:material_id", m.updateMaterial)
-		instructorRoutes.DELETE("materials/:material_id", m.deleteMaterial)
-	}
-	studentInstructorRoutes := m.rg.Group("/courses/:id", m.authMiddleware.RequireToken("student", "instructor"))
+	// Instruktur hanya untuk kursus miliknya, siswa hanya kursus yang diikuti (dicek di usecase)
+	materialRoutes := m.rg.Group("/courses/:id/materials")
 	{
-		studentInstructorRoutes.GET("/materials", m.getAllMaterial)
-		studentInstructorRoutes.GET("/materials/:material_id", m.getMaterialById)
+		materialRoutes.POST("", m.authMiddleware.RequirePermission(policy.MaterialCreate), m.createMaterial)
+		materialRoutes.PUT("/:material_id", m.authMiddleware.RequirePermission(policy.MaterialUpdate), m.updateMaterial)
+		materialRoutes.DELETE("/:material_id", m.authMiddleware.RequirePermission(policy.MaterialDelete), m.deleteMaterial)
+		materialRoutes.GET("", m.authMiddleware.RequirePermission(policy.MaterialRead), m.getAllMaterial)
+		materialRoutes.GET("/:material_id", m.authMiddleware.RequirePermission(policy.MaterialRead), m.getMaterialById)
 	}
 }
 
@@ -34,174 +35,153 @@ func (m *materialController) getAllMaterial(ctx *gin.Context) {
 	courseParam := ctx.Param("id")
 	courseId, err := strconv.Atoi(courseParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course id"})
+		ctx.Error(apperror.BadRequest("invalid course id"))
 		return
 	}
 
-	materials, err := m.useCase.GetAllMaterial(courseId)
+	actor, err := middleware.ExtractUser(ctx)
 	if err != nil {
-		if err.Error() == "course not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		} else if err.Error() == "no materials found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Material not found"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		ctx.Error(err)
+		return
+	}
+
+	page, ok := bindPage(ctx)
+	if !ok {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, struct {
-		Message string           `json:"message"`
-		Data    []model.Material `json:"data"`
-	}{
-		Message: "Material data retrieved successfully",
-		Data:    materials,
-	})
+	materials, total, err := m.useCase.GetAllMaterial(courseId, page, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Paginated(ctx, "Material data retrieved successfully", dto.ToMaterialResponses(materials), page, total)
 }
 
 func (m *materialController) createMaterial(ctx *gin.Context) {
 	courseParam := ctx.Param("id")
 	courseId, err := strconv.Atoi(courseParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course id"})
+		ctx.Error(apperror.BadRequest("invalid course id"))
+		return
+	}
+
+	var payload dto.MaterialDto
+	if !bindJSON(ctx, &payload) {
 		return
 	}
 
-	var payload model.Material
-	err = ctx.ShouldBindJSON(&payload)
+	actor, err := middleware.ExtractUser(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	material, err := m.useCase.CreateMaterial(courseId, &payload)
+	newMaterial := payload.ToModel()
+	material, err := m.useCase.CreateMaterial(courseId, &newMaterial, actor)
 	if err != nil {
-		if err.Error() == "course not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, struct {
-		Message string         `json:"message"`
-		Data    model.Material `json:"data"`
-	}{
-		Message: "Material create successfully",
-		Data:    material,
-	})
+	response.Success(ctx, http.StatusCreated, "Material created successfully", dto.ToMaterialResponse(material))
 }
 
 func (m *materialController) getMaterialById(ctx *gin.Context) {
 	courseParam := ctx.Param("id")
 	courseId, err := strconv.Atoi(courseParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course id"})
+		ctx.Error(apperror.BadRequest("invalid course id"))
 		return
 	}
 
 	materialParam := ctx.Param("material_id")
 	materialId, err := strconv.Atoi(materialParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material id"})
+		ctx.Error(apperror.BadRequest("invalid material id"))
 		return
 	}
 
-	material, err := m.useCase.GetMaterialById(courseId, materialId)
+	actor, err := middleware.ExtractUser(ctx)
 	if err != nil {
-		if err.Error() == "invalid course id" {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course id"})
-		} else if err.Error() == "invalid material id" {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material id"})
-		} else if err.Error() == "material not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Material not found"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, struct {
-		Message string           `json:"message"`
-		Data    model.Material `json:"data"`
-	}{
-		Message: "Material data retrieved successfully",
-		Data:    material,
-	})
+	material, err := m.useCase.GetMaterialById(courseId, materialId, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, "Material data retrieved successfully", dto.ToMaterialResponse(material))
 }
 
 func (m *materialController) updateMaterial(ctx *gin.Context) {
 	courseParam := ctx.Param("id")
 	courseId, err := strconv.Atoi(courseParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course id"})
+		ctx.Error(apperror.BadRequest("invalid course id"))
 		return
 	}
 
 	materialParam := ctx.Param("material_id")
 	materialId, err := strconv.Atoi(materialParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material id"})
+		ctx.Error(apperror.BadRequest("invalid material id"))
 		return
 	}
 
-	var payload model.Material
-	err = ctx.ShouldBindJSON(&payload)
+	var payload dto.MaterialDto
+	if !bindJSON(ctx, &payload) {
+		return
+	}
+
+	actor, err := middleware.ExtractUser(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	material, err := m.useCase.UpdateMaterial(courseId, materialId, &payload)
+	updatedMaterial := payload.ToModel()
+	material, err := m.useCase.UpdateMaterial(courseId, materialId, &updatedMaterial, actor)
 	if err != nil {
-		if err.Error() == "course not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
-		} else if err.Error() == "course not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, struct {
-		Message string         `json:"message"`
-		Data    model.Material `json:"data"`
-	}{
-		Message: "Material create successfully",
-		Data:    material,
-	})
+	response.Success(ctx, http.StatusOK, "Material updated successfully", dto.ToMaterialResponse(material))
 }
 
 func (m *materialController) deleteMaterial(ctx *gin.Context) {
 	courseParam := ctx.Param("id")
 	courseId, err := strconv.Atoi(courseParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course id"})
+		ctx.Error(apperror.BadRequest("invalid course id"))
 		return
 	}
 
 	materialParam := ctx.Param("material_id")
 	materialId, err := strconv.Atoi(materialParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material id"})
+		ctx.Error(apperror.BadRequest("invalid material id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(ctx)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	err = m.useCase.DeleteMaterial(courseId, materialId)
+	err = m.useCase.DeleteMaterial(courseId, materialId, actor)
 	if err != nil {
-		if err.Error() == "course not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		} else if err.Error() == "material not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "Material not found"})
-		} else {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Material deleted successfully"})
+	response.Success(ctx, http.StatusOK, "Material deleted successfully", nil)
 }
 
 func NewMaterialController(useCase usecase.MaterialUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *materialController {
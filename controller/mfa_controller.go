@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"edu-learn/middleware"
+	"edu-learn/model/dto"
+	"edu-learn/usecase"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mfaController struct {
+	useCase        usecase.MFAUseCase
+	rg             *gin.RouterGroup
+	authMiddleware middleware.AuthMiddleware
+}
+
+func (m *mfaController) Route() {
+	userRoutes := m.rg.Group("/mfa", m.authMiddleware.RequireToken("admin", "student", "instructor"), m.authMiddleware.BlockImpersonation())
+	{
+		userRoutes.POST("/enroll", m.enroll)
+		userRoutes.POST("/enroll/confirm", m.confirmEnrollment)
+		userRoutes.POST("/recovery-codes", m.regenerateRecoveryCodes)
+		userRoutes.POST("/disable", m.disable)
+	}
+
+	adminRoutes := m.rg.Group("/mfa/roles", m.authMiddleware.RequirePermission(policy.MFAPolicyManage))
+	{
+		adminRoutes.GET("", m.getRolePolicies)
+		adminRoutes.PUT("/:role", m.setRolePolicy)
+	}
+}
+
+func (m *mfaController) enroll(c *gin.Context) {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	enrollment, err := m.useCase.Enroll(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Scan the provisioning URI with your authenticator app", enrollment)
+}
+
+func (m *mfaController) confirmEnrollment(c *gin.Context) {
+	var payload dto.MFACodeDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recoveryCodes, err := m.useCase.ConfirmEnrollment(userID, payload.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "MFA enabled, store the recovery codes somewhere safe", gin.H{"recovery_codes": recoveryCodes})
+}
+
+func (m *mfaController) regenerateRecoveryCodes(c *gin.Context) {
+	var payload dto.MFACodeDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	recoveryCodes, err := m.useCase.RegenerateRecoveryCodes(userID, payload.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Recovery codes regenerated successfully", gin.H{"recovery_codes": recoveryCodes})
+}
+
+func (m *mfaController) disable(c *gin.Context) {
+	var payload dto.MFACodeDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	user, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = m.useCase.Disable(user, payload.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "MFA disabled successfully", nil)
+}
+
+func (m *mfaController) getRolePolicies(c *gin.Context) {
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	policies, total, err := m.useCase.GetRolePolicies(page)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, "MFA policies retrieved successfully", policies, page, total)
+}
+
+func (m *mfaController) setRolePolicy(c *gin.Context) {
+	var payload dto.MFARolePolicyDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	policy, err := m.useCase.SetRolePolicy(c.Param("role"), *payload.Required)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "MFA policy updated successfully", policy)
+}
+
+func NewMFAController(useCase usecase.MFAUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *mfaController {
+	return &mfaController{useCase: useCase, rg: rg, authMiddleware: authMiddleware}
+}
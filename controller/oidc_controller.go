@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"edu-learn/middleware"
+	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/response"
+	"edu-learn/utils/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type oidcController struct {
+	authUC         usecase.AuthenticationUseCase
+	oidcProvider   service.OIDCProvider
+	rg             *gin.RouterGroup
+	authMiddleware middleware.AuthMiddleware
+}
+
+func (o *oidcController) Route() {
+	oidcRoutes := o.rg.Group("/oidc")
+	{
+		oidcRoutes.GET("/login", o.loginURL)
+		oidcRoutes.GET("/callback", o.callback)
+		oidcRoutes.GET("/identities", o.authMiddleware.RequireToken("admin", "student", "instructor"), o.getIdentities)
+
+		// Halaman login palsu hanya ada saat OIDC_PROVIDER=stub dan OIDC_STUB_ENABLED=true
+		if stub, ok := o.oidcProvider.(*service.StubOIDCProvider); ok {
+			oidcRoutes.GET("/stub/authorize", o.stubAuthorize(stub))
+		}
+	}
+}
+
+func (o *oidcController) loginURL(c *gin.Context) {
+	authorizationURL, err := o.authUC.OIDCLoginURLUseCase()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Redirect the user to the authorization URL", gin.H{"authorization_url": authorizationURL})
+}
+
+func (o *oidcController) callback(c *gin.Context) {
+	// Provider mengembalikan error jika user menolak atau login di provider gagal
+	if providerError := c.Query("error"); providerError != "" {
+		c.Error(apperror.BadRequest("%s: %s", providerError, c.Query("error_description")))
+		return
+	}
+
+	result, err := o.authUC.OIDCCallbackUseCase(c.Query("code"), c.Query("state"), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	writeLoginResult(c, result)
+}
+
+func (o *oidcController) getIdentities(c *gin.Context) {
+	userID, err := middleware.ExtractUserID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	identities, total, err := o.authUC.GetIdentities(userID, page)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, "Identities retrieved successfully", identities, page, total)
+}
+
+// stubAuthorize => Langsung redirect ke callback untuk email di query, contoh: &email=budi@mail.com&name=Budi.
+// Email dianggap belum terverifikasi kecuali ada &email_verified=true
+func (o *oidcController) stubAuthorize(stub *service.StubOIDCProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		redirectURL, err := stub.Authorize(
+			c.Query("email"),
+			c.Query("name"),
+			c.Query("email_verified") == "true",
+			c.Query("state"),
+			c.Query("nonce"),
+			c.Query("code_challenge"),
+		)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.Redirect(http.StatusFound, redirectURL)
+	}
+}
+
+func NewOIDCController(authUC usecase.AuthenticationUseCase, oidcProvider service.OIDCProvider, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *oidcController {
+	return &oidcController{authUC: authUC, oidcProvider: oidcProvider, rg: rg, authMiddleware: authMiddleware}
+}
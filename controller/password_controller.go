@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"edu-learn/model/dto"
+	"edu-learn/usecase"
+	"edu-learn/utils/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type passwordController struct {
+	useCase usecase.PasswordResetUseCase
+	rg      *gin.RouterGroup
+}
+
+func (p *passwordController) Route() {
+	p.rg.POST("/password/forgot", p.forgotPassword)
+	p.rg.POST("/password/reset", p.resetPassword)
+}
+
+func (p *passwordController) forgotPassword(c *gin.Context) {
+	var payload dto.ForgotPasswordDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	err := p.useCase.ForgotPassword(payload.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Response sama untuk email terdaftar maupun tidak
+	response.Success(c, http.StatusOK, "If the email is registered, a password reset link has been sent", nil)
+}
+
+func (p *passwordController) resetPassword(c *gin.Context) {
+	var payload dto.ResetPasswordDto
+
+	if !bindJSON(c, &payload) {
+		return
+	}
+
+	err := p.useCase.ResetPassword(payload.Token, payload.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Password reset successfully", nil)
+}
+
+func NewPasswordController(useCase usecase.PasswordResetUseCase, rg *gin.RouterGroup) *passwordController {
+	return &passwordController{useCase: useCase, rg: rg}
+}
@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"edu-learn/middleware"
+	"edu-learn/model"
+	"edu-learn/model/dto"
+	"edu-learn/repository"
+	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
+	"edu-learn/utils/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type paymentController struct {
+	useCase               usecase.PaymentUseCase
+	rg                    *gin.RouterGroup
+	authMiddleware        middleware.AuthMiddleware
+	idempotencyMiddleware middleware.IdempotencyMiddleware
+}
+
+func (p *paymentController) Route() {
+	// Dipanggil oleh payment gateway, diverifikasi dengan signature HMAC
+	p.rg.POST("/payments/webhook", p.paymentWebhook)
+
+	p.rg.POST("/payments/", p.authMiddleware.RequirePermission(policy.PaymentCreate), p.authMiddleware.BlockImpersonation(), p.idempotencyMiddleware.Handle(), p.createPayment)
+	p.rg.GET("/payments/:id", p.authMiddleware.RequirePermission(policy.PaymentRead), p.getPaymentById)
+
+	p.rg.GET("/users/:id/payments", p.authMiddleware.RequirePermission(policy.PaymentRead), p.getPaymentsByUserID)
+
+	refundRoutes := p.rg.Group("/payments/:id/refunds", p.authMiddleware.RequirePermission(policy.PaymentRefund))
+	{
+		refundRoutes.POST("", p.authMiddleware.BlockImpersonation(), p.refundPayment)
+		refundRoutes.GET("", p.getRefundsByPaymentID)
+	}
+}
+
+func (p *paymentController) createPayment(ctx *gin.Context) {
+	var payload dto.PaymentDto
+
+	if !bindJSON(ctx, &payload) {
+		return
+	}
+
+	userID, err := middleware.ExtractUserID(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	payment, err := p.useCase.CreatePayment(&model.Payment{
+		StudentID: userID,
+		CourseID:  payload.CourseID,
+		Amount:    payload.Amount,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Success(ctx, http.StatusCreated, "Payment created successfully", dto.ToPaymentResponse(payment))
+}
+
+func (p *paymentController) getPaymentById(ctx *gin.Context) {
+	id := ctx.Param("id")
+	paymentId, err := strconv.Atoi(id)
+	if err != nil {
+		ctx.Error(apperror.BadRequest("invalid payment id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	payment, err := p.useCase.GetPaymentById(paymentId, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, "Payment data retrieved successfully", dto.ToPaymentResponse(payment))
+}
+
+func (p *paymentController) getPaymentsByUserID(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	userID, err := strconv.Atoi(idParam)
+	if err != nil {
+		ctx.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	payments, total, err := p.useCase.GetPaymentsByUserID(userID, page, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Paginated(ctx, "Payment data retrieved successfully", dto.ToPaymentResponses(payments), page, total)
+}
+
+func (p *paymentController) paymentWebhook(ctx *gin.Context) {
+	payload, err := ctx.GetRawData()
+	if err != nil {
+		ctx.Error(apperror.BadRequest("invalid webhook payload"))
+		return
+	}
+
+	err = p.useCase.HandleWebhook(ctx.Request.Header, payload)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateEvent) {
+			response.Success(ctx, http.StatusOK, "Event already processed", nil)
+			return
+		}
+		if errors.Is(err, service.ErrWebhookIgnored) {
+			response.Success(ctx, http.StatusOK, "Event ignored", nil)
+			return
+		}
+
+		ctx.Error(err)
+		return
+	}
+
+	response.Success(ctx, http.StatusOK, "Webhook processed successfully", nil)
+}
+
+func (p *paymentController) refundPayment(ctx *gin.Context) {
+	id := ctx.Param("id")
+	paymentId, err := strconv.Atoi(id)
+	if err != nil {
+		ctx.Error(apperror.BadRequest("invalid payment id"))
+		return
+	}
+
+	var payload dto.RefundDto
+	if !bindJSON(ctx, &payload) {
+		return
+	}
+
+	actor, err := middleware.ExtractUser(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	refund, err := p.useCase.RefundPayment(paymentId, &model.Refund{Amount: payload.Amount, Reason: payload.Reason}, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Success(ctx, http.StatusCreated, "Payment refunded successfully", dto.ToRefundResponse(refund))
+}
+
+func (p *paymentController) getRefundsByPaymentID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	paymentId, err := strconv.Atoi(id)
+	if err != nil {
+		ctx.Error(apperror.BadRequest("invalid payment id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	refunds, total, err := p.useCase.GetRefundsByPaymentID(paymentId, page, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response.Paginated(ctx, "Refund data retrieved successfully", dto.ToRefundResponses(refunds), page, total)
+}
+
+func NewPaymentController(useCase usecase.PaymentUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware, idempotencyMiddleware middleware.IdempotencyMiddleware) *paymentController {
+	return &paymentController{useCase: useCase, rg: rg, authMiddleware: authMiddleware, idempotencyMiddleware: idempotencyMiddleware}
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"edu-learn/config"
+	"edu-learn/middleware"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var webhookSecret = []byte("test-webhook-secret")
+
+// fakePaymentRepository => Meniru aturan ApplyPaymentEvent di database: event_id unik dan transisi status
+type fakePaymentRepository struct {
+	repository.PaymentRepository
+	payments map[string]*model.Payment
+	events   map[string]bool
+	enrolled map[int]bool
+}
+
+func (f *fakePaymentRepository) GetPaymentByGatewayRef(ref string) (model.Payment, error) {
+	payment, ok := f.payments[ref]
+	if !ok {
+		return model.Payment{}, apperror.NotFound("payment not found")
+	}
+	return *payment, nil
+}
+
+func (f *fakePaymentRepository) ApplyPaymentEvent(id int, eventID string, status string) (model.Payment, error) {
+	if f.events[eventID] {
+		return model.Payment{}, repository.ErrDuplicateEvent
+	}
+
+	for _, payment := range f.payments {
+		if payment.ID != id {
+			continue
+		}
+
+		if payment.Status != status && !payment.CanTransitionTo(status) {
+			return model.Payment{}, apperror.Conflict("invalid status transition")
+		}
+
+		// Event hanya tersimpan jika transaksi berhasil
+		f.events[eventID] = true
+		payment.Status = status
+		f.enrolled[payment.ID] = status == model.PaymentStatusCompleted
+		return *payment, nil
+	}
+
+	return model.Payment{}, apperror.NotFound("payment not found")
+}
+
+type passThroughAuthMiddleware struct{}
+
+func (passThroughAuthMiddleware) RequireToken(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) { c.Next() }
+}
+
+func (passThroughAuthMiddleware) RequirePermission(permission policy.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) { c.Next() }
+}
+
+func (passThroughAuthMiddleware) BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) { c.Next() }
+}
+
+func newWebhookServer(t *testing.T, repo *fakePaymentRepository) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	useCase := usecase.NewPaymentUseCase(repo, nil, nil, nil, service.NewFakePaymentGateway(model.PaymentStatusPending, webhookSecret), config.PaymentConfig{WebhookSecret: webhookSecret})
+
+	engine := gin.New()
+	engine.Use(middleware.ErrorHandler())
+	NewPaymentController(useCase, engine.Group("/api"), passThroughAuthMiddleware{}, middleware.NewIdempotencyMiddleware(nil)).Route()
+
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newPaymentRepository(status string) *fakePaymentRepository {
+	return &fakePaymentRepository{
+		payments: map[string]*model.Payment{"EDU-1": {ID: 1, StudentID: 7, CourseID: 3, Amount: 150000, Status: status, GatewayRef: "EDU-1"}},
+		events:   map[string]bool{},
+		enrolled: map[int]bool{},
+	}
+}
+
+func sendWebhook(t *testing.T, sender service.FakeWebhookSender, eventID, reference, status string) int {
+	t.Helper()
+
+	resp, err := sender.Send(eventID, reference, status)
+	if err != nil {
+		t.Fatalf("send webhook: %v", err)
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	tests := []struct {
+		name          string
+		initialStatus string
+		secret        []byte
+		reference     string
+		status        string
+		wantCode      int
+		wantStatus    string
+	}{
+		{"pending to completed", model.PaymentStatusPending, webhookSecret, "EDU-1", model.PaymentStatusCompleted, http.StatusOK, model.PaymentStatusCompleted},
+		{"pending to failed", model.PaymentStatusPending, webhookSecret, "EDU-1", model.PaymentStatusFailed, http.StatusOK, model.PaymentStatusFailed},
+		{"completed to refunded", model.PaymentStatusCompleted, webhookSecret, "EDU-1", model.PaymentStatusRefunded, http.StatusOK, model.PaymentStatusRefunded},
+		{"same status is a redelivery", model.PaymentStatusCompleted, webhookSecret, "EDU-1", model.PaymentStatusCompleted, http.StatusOK, model.PaymentStatusCompleted},
+		{"bad signature", model.PaymentStatusPending, []byte("wrong-secret"), "EDU-1", model.PaymentStatusCompleted, http.StatusUnauthorized, model.PaymentStatusPending},
+		{"completed back to pending", model.PaymentStatusCompleted, webhookSecret, "EDU-1", model.PaymentStatusPending, http.StatusConflict, model.PaymentStatusCompleted},
+		{"failed to completed", model.PaymentStatusFailed, webhookSecret, "EDU-1", model.PaymentStatusCompleted, http.StatusConflict, model.PaymentStatusFailed},
+		{"refunded to completed", model.PaymentStatusRefunded, webhookSecret, "EDU-1", model.PaymentStatusCompleted, http.StatusConflict, model.PaymentStatusRefunded},
+		{"unknown status", model.PaymentStatusPending, webhookSecret, "EDU-1", "paid", http.StatusBadRequest, model.PaymentStatusPending},
+		{"unknown reference", model.PaymentStatusPending, webhookSecret, "EDU-404", model.PaymentStatusCompleted, http.StatusNotFound, model.PaymentStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newPaymentRepository(tt.initialStatus)
+			server := newWebhookServer(t, repo)
+			sender := service.NewFakeWebhookSender(server.URL+"/api/payments/webhook", tt.secret)
+
+			code := sendWebhook(t, sender, "evt-1", tt.reference, tt.status)
+			if code != tt.wantCode {
+				t.Errorf("status code = %d, want %d", code, tt.wantCode)
+			}
+			if got := repo.payments["EDU-1"].Status; got != tt.wantStatus {
+				t.Errorf("payment status = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPaymentWebhookDuplicateEvent(t *testing.T) {
+	repo := newPaymentRepository(model.PaymentStatusPending)
+	server := newWebhookServer(t, repo)
+	sender := service.NewFakeWebhookSender(server.URL+"/api/payments/webhook", webhookSecret)
+
+	if code := sendWebhook(t, sender, "evt-1", "EDU-1", model.PaymentStatusCompleted); code != http.StatusOK {
+		t.Fatalf("first delivery status code = %d, want %d", code, http.StatusOK)
+	}
+	if !repo.enrolled[1] {
+		t.Fatalf("completed payment did not enroll the student")
+	}
+
+	// Event yang sama dikirim ulang dengan status lain tetap diabaikan
+	if code := sendWebhook(t, sender, "evt-1", "EDU-1", model.PaymentStatusRefunded); code != http.StatusOK {
+		t.Errorf("duplicate delivery status code = %d, want %d", code, http.StatusOK)
+	}
+	if got := repo.payments["EDU-1"].Status; got != model.PaymentStatusCompleted {
+		t.Errorf("payment status after duplicate = %s, want %s", got, model.PaymentStatusCompleted)
+	}
+
+	// Transisi ilegal ditolak dan event-nya tidak tersimpan
+	if code := sendWebhook(t, sender, "evt-2", "EDU-1", model.PaymentStatusFailed); code != http.StatusConflict {
+		t.Errorf("illegal transition status code = %d, want %d", code, http.StatusConflict)
+	}
+	if repo.events["evt-2"] {
+		t.Errorf("rejected event was recorded")
+	}
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"edu-learn/middleware"
+	"edu-learn/model"
+	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type sessionController struct {
+	useCase        usecase.SessionUseCase
+	rg             *gin.RouterGroup
+	authMiddleware middleware.AuthMiddleware
+}
+
+func (s *sessionController) Route() {
+	meRoutes := s.rg.Group("/users/me/sessions", s.authMiddleware.RequireToken("admin", "student", "instructor"))
+	{
+		meRoutes.GET("", s.getMySessions)
+		meRoutes.DELETE("", s.authMiddleware.BlockImpersonation(), s.revokeAllMySessions)
+		meRoutes.DELETE("/:sessionId", s.authMiddleware.BlockImpersonation(), s.revokeMySession)
+	}
+
+	adminRoutes := s.rg.Group("/users/:id/sessions", s.authMiddleware.RequirePermission(policy.SessionManage))
+	{
+		adminRoutes.GET("", s.getSessions)
+		adminRoutes.DELETE("", s.authMiddleware.BlockImpersonation(), s.revokeAllSessions)
+		adminRoutes.DELETE("/:sessionId", s.authMiddleware.BlockImpersonation(), s.revokeSession)
+	}
+}
+
+func (s *sessionController) getMySessions(c *gin.Context) {
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	s.writeSessions(c, actor.ID, actor)
+}
+
+func (s *sessionController) revokeMySession(c *gin.Context) {
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	s.writeRevokeSession(c, actor.ID, actor)
+}
+
+func (s *sessionController) revokeAllMySessions(c *gin.Context) {
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	s.writeRevokeAllSessions(c, actor.ID, actor)
+}
+
+func (s *sessionController) getSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	s.writeSessions(c, userID, actor)
+}
+
+func (s *sessionController) revokeSession(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	s.writeRevokeSession(c, userID, actor)
+}
+
+func (s *sessionController) revokeAllSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	s.writeRevokeAllSessions(c, userID, actor)
+}
+
+// writeSessions => Dipakai endpoint /users/me dan endpoint admin
+func (s *sessionController) writeSessions(c *gin.Context, userID int, actor model.User) {
+	// Akses lewat API key tidak punya claims, jadi tidak ada sesi yang ditandai current
+	claims, _ := middleware.ExtractClaims(c)
+
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	sessions, total, err := s.useCase.GetSessions(userID, claims.SessionID, page, actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, "Sessions retrieved successfully", sessions, page, total)
+}
+
+func (s *sessionController) writeRevokeSession(c *gin.Context, userID int, actor model.User) {
+	err := s.useCase.RevokeSession(userID, c.Param("sessionId"), actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Session revoked successfully", nil)
+}
+
+func (s *sessionController) writeRevokeAllSessions(c *gin.Context, userID int, actor model.User) {
+	err := s.useCase.RevokeAllSessions(userID, actor)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "All sessions revoked successfully", nil)
+}
+
+func NewSessionController(useCase usecase.SessionUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *sessionController {
+	return &sessionController{useCase: useCase, rg: rg, authMiddleware: authMiddleware}
+}
@@ -2,8 +2,11 @@ package controller
 
 import (
 	"edu-learn/middleware"
-	"edu-learn/model"
+	"edu-learn/model/dto"
 	"edu-learn/usecase"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
 	"net/http"
 	"strconv"
 
@@ -17,122 +20,99 @@ type userController struct {
 }
 
 func (u *userController) Route() {
-	// Grup route untuk admin
-	adminRoutes := u.rg.Group("/users", u.authMiddleware.RequireToken("admin"))
+	userRoutes := u.rg.Group("/users")
 	{
-		adminRoutes.GET("/", u.getAllUsers)
-		adminRoutes.DELETE("/:id", u.deleteUser)
-	}
-	adminUserRoutes := u.rg.Group("/users", u.authMiddleware.RequireToken("admin", "student", "instructor"))
-	{
-		adminUserRoutes.GET("/:id", u.getUserById)
-		adminRoutes.PUT("/:id", u.updateUser)
+		userRoutes.GET("/", u.authMiddleware.RequirePermission(policy.UserList), u.getAllUsers)
+		userRoutes.DELETE("/:id", u.authMiddleware.RequirePermission(policy.UserDelete), u.authMiddleware.BlockImpersonation(), u.deleteUser)
+		// Selain admin hanya bisa melihat data dirinya sendiri
+		userRoutes.GET("/:id", u.authMiddleware.RequirePermission(policy.UserRead), u.getUserById)
+		userRoutes.PUT("/:id", u.authMiddleware.RequirePermission(policy.UserUpdate), u.authMiddleware.BlockImpersonation(), u.updateUser)
 	}
 }
 
 // Method untuk user controller
 func (u *userController) getAllUsers(c *gin.Context) {
-	user, err := u.useCase.GetAllUsers()
+	page, ok := bindPage(c)
+	if !ok {
+		return
+	}
+
+	user, total, err := u.useCase.GetAllUsers(page)
 	if err != nil {
-		if err.Error() == "no users found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "No users found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, struct {
-		Message string       `json:"message"`
-		Data    []model.User `json:"data"`
-	}{
-		Message: "User data retrieved successfully",
-		Data:    user,
-	})
+	response.Paginated(c, "User data retrieved successfully", dto.ToUserResponses(user), page, total)
 }
 
 func (u *userController) getUserById(c *gin.Context) {
 	id := c.Param("id")
 	userId, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		c.Error(apperror.BadRequest("invalid user id"))
+		return
+	}
+
+	actor, err := middleware.ExtractUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = policy.Authorize(actor, policy.UserRead, actor.ID == userId)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	user, err := u.useCase.GetUserById(userId)
 	if err != nil {
-		if err.Error() == "user not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, struct {
-		Message string     `json:"message"`
-		Data    model.User `json:"data"`
-	}{
-		Message: "User data retrieved successfully",
-		Data:    user,
-	})
+	response.Success(c, http.StatusOK, "User data retrieved successfully", dto.ToUserResponse(user))
 }
 
 func (u *userController) updateUser(c *gin.Context) {
 	id := c.Param("id")
 	userId, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		c.Error(apperror.BadRequest("invalid user id"))
 		return
 	}
 
-	var payload model.User
-	err = c.ShouldBindJSON(&payload)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var payload dto.UpdateUserDto
+	if !bindJSON(c, &payload) {
 		return
 	}
 
-	user, err := u.useCase.UpdateUser(userId, &payload)
+	updatedUser := payload.ToModel()
+	user, err := u.useCase.UpdateUser(userId, &updatedUser)
 	if err != nil {
-		if err.Error() == "user not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		} else if err.Error() == "you may not change your role" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, struct {
-		Message string     `json:"message"`
-		Data    model.User `json:"data"`
-	}{
-		Message: "User updated successfully",
-		Data:    user,
-	})
+	response.Success(c, http.StatusOK, "User updated successfully", dto.ToUserResponse(user))
 }
 
 func (u *userController) deleteUser(c *gin.Context) {
 	id := c.Param("id")
 	userId, err := strconv.Atoi(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		c.Error(apperror.BadRequest("invalid user id"))
 		return
 	}
 
 	err = u.useCase.DeleteUser(userId)
 	if err != nil {
-		if err.Error() == "user not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+	response.Success(c, http.StatusOK, "User deleted successfully", nil)
 }
 
 func NewUserController(useCase usecase.UserUseCase, rg *gin.RouterGroup, authMiddleware middleware.AuthMiddleware) *userController {
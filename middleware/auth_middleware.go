@@ -2,8 +2,13 @@ package middleware
 
 import (
 	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/response"
 	"edu-learn/utils/service"
-	"fmt"
+	"log"
 	"net/http"
 	"strings"
 
@@ -11,7 +16,10 @@ import (
 )
 
 type authMiddleware struct {
-	jwtService service.JwtService
+	jwtService        service.JwtService
+	revocationService service.TokenRevocationService
+	apiKeyService     service.APIKeyService
+	repoImpersonation repository.ImpersonationRepository
 }
 
 type authHeader struct {
@@ -20,27 +28,17 @@ type authHeader struct {
 
 type AuthMiddleware interface {
 	RequireToken(roles ...string) gin.HandlerFunc
+	RequirePermission(permission policy.Permission) gin.HandlerFunc
+	BlockImpersonation() gin.HandlerFunc
 }
 
 func (a *authMiddleware) RequireToken(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var aH authHeader
-
-		err := c.ShouldBindHeader(&aH)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Unautorized"})
-			return
-		}
-
-		token := strings.Replace(aH.AuthorizationHeader, "Bearer ", "", 1)
-
-		tokenClaim, err := a.jwtService.VerifyToken(token)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Unautorized"})
+		tokenClaim, ok := a.authenticate(c)
+		if !ok {
 			return
 		}
-
-		c.Set("user", model.User{ID: tokenClaim.UserId, Role: tokenClaim.Role})
+		defer a.auditImpersonation(c, tokenClaim)
 
 		validRole := false
 		for _, role := range roles {
@@ -50,7 +48,70 @@ func (a *authMiddleware) RequireToken(roles ...string) gin.HandlerFunc {
 			}
 		}
 		if !validRole {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Forbiden Resourse"})
+			response.Error(c, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission => Cek role punya permission, kepemilikan resource dicek di usecase
+func (a *authMiddleware) RequirePermission(permission policy.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// API key hanya berlaku di route yang memakai permission, route RequireToken tetap butuh JWT
+		if c.GetHeader("X-API-Key") != "" {
+			a.requireAPIKey(c, permission)
+			return
+		}
+
+		tokenClaim, ok := a.authenticate(c)
+		if !ok {
+			return
+		}
+		defer a.auditImpersonation(c, tokenClaim)
+
+		if !policy.Allows(tokenClaim.Role, permission) {
+			response.Error(c, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// requireAPIKey => Permission harus ada di scope key dan masih dimiliki role pemilik key
+func (a *authMiddleware) requireAPIKey(c *gin.Context, permission policy.Permission) {
+	apiKey, err := a.apiKeyService.Authenticate(c.GetHeader("X-API-Key"))
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	inScope := false
+	for _, scope := range apiKey.Scopes {
+		if policy.Permission(scope) == permission {
+			inScope = true
+			break
+		}
+	}
+	if !inScope || !policy.Delegable(apiKey.User.Role, permission) {
+		response.Error(c, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	c.Set("user", model.User{ID: apiKey.UserID, Role: apiKey.User.Role})
+	c.Set("api_key", apiKey)
+
+	c.Next()
+}
+
+// BlockImpersonation => Dipasang setelah RequireToken/RequirePermission di route sensitif (password, pembayaran, dan sejenisnya)
+func (a *authMiddleware) BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := ExtractClaims(c)
+		if err == nil && claims.Act != nil {
+			response.Error(c, http.StatusForbidden, "This action is not allowed while impersonating")
 			return
 		}
 
@@ -58,25 +119,118 @@ func (a *authMiddleware) RequireToken(roles ...string) gin.HandlerFunc {
 	}
 }
 
-func NewAuthMiddleware(jwtService service.JwtService) AuthMiddleware {
-	return &authMiddleware{jwtService: jwtService}
+// auditImpersonation => Dipanggil dengan defer supaya request yang ditolak juga tercatat
+func (a *authMiddleware) auditImpersonation(c *gin.Context, tokenClaim modelutils.JWTPayloadClaim) {
+	if tokenClaim.Act == nil {
+		return
+	}
+
+	// Error dari c.Error ditulis dulu supaya status yang dicatat sama dengan yang diterima client,
+	// panic dicatat sebagai 500 lalu diteruskan ke Recovery
+	recovered := recover()
+	statusCode := http.StatusInternalServerError
+	if recovered == nil {
+		WriteError(c)
+		statusCode = c.Writer.Status()
+	}
+
+	userID := tokenClaim.UserId
+	impersonatorID := tokenClaim.Act.UserId
+	err := a.repoImpersonation.CreateAuditLog(&model.ImpersonationAuditLog{
+		TokenID:        tokenClaim.ID,
+		ImpersonatorID: &impersonatorID,
+		UserID:         &userID,
+		Method:         c.Request.Method,
+		Path:           c.Request.URL.RequestURI(),
+		StatusCode:     statusCode,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+	})
+	if err != nil {
+		log.Printf("failed to write impersonation audit log: %v", err)
+	}
+
+	if recovered != nil {
+		panic(recovered)
+	}
+}
+
+// authenticate => Verifikasi token lalu simpan user dan claim di Context
+func (a *authMiddleware) authenticate(c *gin.Context) (modelutils.JWTPayloadClaim, bool) {
+	var aH authHeader
+
+	err := c.ShouldBindHeader(&aH)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return modelutils.JWTPayloadClaim{}, false
+	}
+
+	token := strings.Replace(aH.AuthorizationHeader, "Bearer ", "", 1)
+
+	tokenClaim, err := a.jwtService.VerifyToken(token)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return modelutils.JWTPayloadClaim{}, false
+	}
+
+	// Token yang sudah logout atau dicabut tidak boleh dipakai lagi
+	revoked, err := a.revocationService.IsRevoked(tokenClaim)
+	if err != nil || revoked {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return modelutils.JWTPayloadClaim{}, false
+	}
+
+	// Token impersonation ikut tidak berlaku jika token milik admin yang memintanya dicabut
+	if tokenClaim.Act != nil {
+		actorClaim := tokenClaim
+		actorClaim.UserId = tokenClaim.Act.UserId
+		revoked, err = a.revocationService.IsRevoked(actorClaim)
+		if err != nil || revoked {
+			response.Error(c, http.StatusUnauthorized, "Unauthorized")
+			return modelutils.JWTPayloadClaim{}, false
+		}
+	}
+
+	c.Set("user", model.User{ID: tokenClaim.UserId, Role: tokenClaim.Role})
+	c.Set("claims", tokenClaim)
+
+	return tokenClaim, true
+}
+
+func NewAuthMiddleware(jwtService service.JwtService, revocationService service.TokenRevocationService, apiKeyService service.APIKeyService, repoImpersonation repository.ImpersonationRepository) AuthMiddleware {
+	return &authMiddleware{jwtService: jwtService, revocationService: revocationService, apiKeyService: apiKeyService, repoImpersonation: repoImpersonation}
 }
 
 // ExtractUser => Ambil user dari Context
 func ExtractUser(c *gin.Context) (model.User, error) {
 	user, exists := c.Get("user")
 	if !exists {
-		return model.User{}, fmt.Errorf("user not found in context")
+		return model.User{}, apperror.Unauthorized("user not found in context")
 	}
 
 	userData, ok := user.(model.User)
 	if !ok {
-		return model.User{}, fmt.Errorf("invalid user data type")
+		return model.User{}, apperror.Unauthorized("invalid user data type")
 	}
 
 	return userData, nil
 }
 
+// ExtractClaims => Ambil claim JWT dari Context
+func ExtractClaims(c *gin.Context) (modelutils.JWTPayloadClaim, error) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return modelutils.JWTPayloadClaim{}, apperror.Unauthorized("claims not found in context")
+	}
+
+	claimData, ok := claims.(modelutils.JWTPayloadClaim)
+	if !ok {
+		return modelutils.JWTPayloadClaim{}, apperror.Unauthorized("invalid claims data type")
+	}
+
+	return claimData, nil
+}
+
 // ExtractUserID => Ambil userID dari Context
 func ExtractUserID(c *gin.Context) (int, error) {
 	user, err := ExtractUser(c)
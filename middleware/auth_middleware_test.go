@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeJwtService => Semua token dianggap token impersonation admin 1 atas nama user 7
+type fakeJwtService struct {
+	service.JwtService
+}
+
+func (fakeJwtService) VerifyToken(tokenString string) (modelutils.JWTPayloadClaim, error) {
+	claim := modelutils.JWTPayloadClaim{UserId: 7, Role: "admin", Act: &modelutils.ActClaim{UserId: 1}}
+	claim.ID = "jti-1"
+	return claim, nil
+}
+
+type fakeRevocationService struct {
+	service.TokenRevocationService
+}
+
+func (fakeRevocationService) IsRevoked(claim modelutils.JWTPayloadClaim) (bool, error) {
+	return false, nil
+}
+
+type fakeImpersonationRepository struct {
+	repository.ImpersonationRepository
+	logs []model.ImpersonationAuditLog
+}
+
+func (f *fakeImpersonationRepository) CreateAuditLog(log *model.ImpersonationAuditLog) error {
+	f.logs = append(f.logs, *log)
+	return nil
+}
+
+func TestImpersonationAuditStatusCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		wantCode int
+	}{
+		{"success", func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{}) }, http.StatusCreated},
+		{"handler error", func(c *gin.Context) { c.Error(apperror.NotFound("course not found")) }, http.StatusNotFound},
+		{"conflict", func(c *gin.Context) { c.Error(apperror.Conflict("course already exists")) }, http.StatusConflict},
+		{"panic", func(c *gin.Context) { panic("boom") }, http.StatusInternalServerError},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		for _, route := range []string{"/token", "/permission"} {
+			t.Run(tt.name+route, func(t *testing.T) {
+				repo := &fakeImpersonationRepository{}
+				auth := NewAuthMiddleware(fakeJwtService{}, fakeRevocationService{}, nil, repo)
+
+				engine := gin.New()
+				engine.Use(gin.Recovery(), ErrorHandler())
+				engine.POST("/token", auth.RequireToken("admin"), tt.handler)
+				engine.POST("/permission", auth.RequirePermission(policy.CourseCreate), tt.handler)
+
+				req := httptest.NewRequest(http.MethodPost, route, nil)
+				req.Header.Set("Authorization", "Bearer impersonation-token")
+				rec := httptest.NewRecorder()
+				engine.ServeHTTP(rec, req)
+
+				if rec.Code != tt.wantCode {
+					t.Errorf("status code = %d, want %d", rec.Code, tt.wantCode)
+				}
+				if len(repo.logs) != 1 {
+					t.Fatalf("audit logs = %d, want 1", len(repo.logs))
+				}
+				if got := repo.logs[0].StatusCode; got != rec.Code {
+					t.Errorf("audited status code = %d, client got %d", got, rec.Code)
+				}
+			})
+		}
+	}
+}
+
+// fakeAPIKeyService => Header X-API-Key berisi role pemilik key, semua key punya scope course:create
+type fakeAPIKeyService struct {
+	service.APIKeyService
+}
+
+func (fakeAPIKeyService) Authenticate(key string) (model.APIKey, error) {
+	if key == "invalid" {
+		return model.APIKey{}, apperror.Unauthorized("invalid api key")
+	}
+	return model.APIKey{ID: 1, UserID: 9, User: &model.User{ID: 9, Role: key}, Scopes: []string{string(policy.CourseCreate)}}, nil
+}
+
+func TestRequirePermissionAPIKeyScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		permission policy.Permission
+		wantCode   int
+	}{
+		{"permission in scope", "instructor", policy.CourseCreate, http.StatusOK},
+		{"permission outside scope", "instructor", policy.MaterialCreate, http.StatusForbidden},
+		{"owner role no longer has the permission", "student", policy.CourseCreate, http.StatusForbidden},
+		{"invalid key", "invalid", policy.CourseCreate, http.StatusUnauthorized},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := NewAuthMiddleware(fakeJwtService{}, fakeRevocationService{}, fakeAPIKeyService{}, &fakeImpersonationRepository{})
+
+			engine := gin.New()
+			engine.Use(ErrorHandler())
+			engine.GET("/", auth.RequirePermission(tt.permission), func(c *gin.Context) {
+				user, err := ExtractUser(c)
+				if err != nil || user.ID != 9 {
+					t.Errorf("handler user = %+v, %v, want key owner 9", user, err)
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-API-Key", tt.key)
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status code = %d, want %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/response"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler => Controller cukup memanggil c.Error(err), status dan body dibuat di sini
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		WriteError(c)
+	}
+}
+
+// WriteError => Balas error terakhir di c.Errors jika response belum ditulis.
+// Dipanggil juga oleh middleware yang perlu melihat response error sebelum ErrorHandler (misalnya idempotency)
+func WriteError(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	err := c.Errors.Last().Err
+	status := apperror.StatusCode(err)
+
+	// Pesan error internal (query gagal dan sejenisnya) tidak dikirim ke client
+	if status == http.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		response.Error(c, status, "Internal server error")
+		return
+	}
+
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) && len(appErr.Fields) > 0 {
+		response.ErrorWithFields(c, status, err.Error(), appErr.Fields)
+		return
+	}
+
+	response.Error(c, status, err.Error())
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/response"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const idempotencyKeyTTL = 24 * time.Hour
+
+type idempotencyMiddleware struct {
+	repo repository.IdempotencyRepository
+}
+
+type IdempotencyMiddleware interface {
+	Handle() gin.HandlerFunc
+}
+
+// responseRecorder => Menyalin body response supaya bisa disimpan
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Handle => Harus dipasang setelah RequireToken karena key disimpan per user
+func (i *idempotencyMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > 255 {
+			response.Error(c, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		userID, err := ExtractUserID(c)
+		if err != nil {
+			response.Error(c, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		record, reserved, err := i.repo.ReserveKey(&model.IdempotencyKey{
+			Key:         key,
+			UserID:      userID,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			RequestHash: requestHash,
+		}, idempotencyKeyTTL)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if !reserved {
+			if record.RequestHash != requestHash {
+				response.Error(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+				return
+			}
+
+			if record.StatusCode == 0 {
+				response.Error(c, http.StatusConflict, "A request with this Idempotency-Key is still being processed")
+				return
+			}
+
+			// Kirim ulang response yang tersimpan
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, "application/json; charset=utf-8", record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+
+		// Recovery gin ada di luar middleware ini, jadi key dilepas di sini juga saat handler panic.
+		// Tanpa ini key tertahan di status_code 0 dan setiap retry dibalas 409 sampai kedaluwarsa
+		defer func() {
+			recovered := recover()
+			if recovered != nil {
+				i.releaseKey(record.ID)
+				panic(recovered)
+			}
+
+			// Error dari c.Error ditulis sekarang supaya ikut terekam
+			WriteError(c)
+			i.saveResponse(record.ID, recorder)
+		}()
+
+		c.Next()
+	}
+}
+
+// saveResponse => Error server tidak disimpan supaya client bisa mencoba lagi dengan key yang sama
+func (i *idempotencyMiddleware) saveResponse(id int, recorder *responseRecorder) {
+	if recorder.Status() >= http.StatusInternalServerError {
+		i.releaseKey(id)
+		return
+	}
+
+	err := i.repo.SaveResponse(id, recorder.Status(), recorder.body.Bytes())
+	if err != nil {
+		// Response sudah terkirim tapi tidak bisa di-replay, lebih aman key dilepas daripada tertahan
+		log.Printf("failed to save idempotent response %d: %v", id, err)
+		i.releaseKey(id)
+	}
+}
+
+func (i *idempotencyMiddleware) releaseKey(id int) {
+	err := i.repo.DeleteKey(id)
+	if err != nil {
+		log.Printf("failed to release idempotency key %d, retries get 409 until it expires: %v", id, err)
+	}
+}
+
+func NewIdempotencyMiddleware(repo repository.IdempotencyRepository) IdempotencyMiddleware {
+	return &idempotencyMiddleware{repo: repo}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeIdempotencyRepository struct {
+	saveErr error
+	saved   map[int]int
+	deleted []int
+}
+
+func (f *fakeIdempotencyRepository) ReserveKey(record *model.IdempotencyKey, ttl time.Duration) (model.IdempotencyKey, bool, error) {
+	record.ID = 1
+	return *record, true, nil
+}
+
+func (f *fakeIdempotencyRepository) SaveResponse(id int, statusCode int, body []byte) error {
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+	f.saved[id] = statusCode
+	return nil
+}
+
+func (f *fakeIdempotencyRepository) DeleteKey(id int) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func TestIdempotencyKeyLifecycle(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     gin.HandlerFunc
+		saveErr     error
+		wantCode    int
+		wantSaved   bool
+		wantDeleted bool
+	}{
+		{"success is saved", func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{}) }, nil, http.StatusCreated, true, false},
+		{"client error is saved", func(c *gin.Context) { c.Error(apperror.Conflict("already enrolled")) }, nil, http.StatusConflict, true, false},
+		{"server error releases key", func(c *gin.Context) { c.Error(errors.New("db down")) }, nil, http.StatusInternalServerError, false, true},
+		{"panic releases key", func(c *gin.Context) { panic("boom") }, nil, http.StatusInternalServerError, false, true},
+		{"failed save releases key", func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{}) }, errors.New("db down"), http.StatusCreated, false, true},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeIdempotencyRepository{saveErr: tt.saveErr, saved: map[int]int{}}
+
+			engine := gin.New()
+			engine.Use(gin.Recovery(), ErrorHandler())
+			engine.POST("/payments",
+				func(c *gin.Context) { c.Set("user", model.User{ID: 7}) },
+				NewIdempotencyMiddleware(repo).Handle(),
+				tt.handler,
+			)
+
+			req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"course_id":1}`))
+			req.Header.Set("Idempotency-Key", "key-1")
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("status code = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if _, saved := repo.saved[1]; saved != tt.wantSaved {
+				t.Errorf("response saved = %v, want %v", saved, tt.wantSaved)
+			}
+			if deleted := len(repo.deleted) == 1; deleted != tt.wantDeleted {
+				t.Errorf("key released = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// API key disimpan dalam bentuk hash, prefix dipakai untuk mencari key dan mengenali key di log
+type APIKey struct {
+	ID         int            `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     int            `gorm:"column:user_id;not null;index" json:"user_id"`
+	User       *User          `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Name       string         `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix     string         `gorm:"type:varchar(32);not null;unique" json:"prefix"`
+	KeyHash    string         `gorm:"column:key_hash;type:varchar(64);not null;unique" json:"-"`
+	Scopes     pq.StringArray `gorm:"type:text[];not null" json:"scopes"`
+	ExpiresAt  *time.Time     `gorm:"column:expires_at" json:"expires_at"`
+	LastUsedAt *time.Time     `gorm:"column:last_used_at" json:"last_used_at"`
+	RevokedAt  *time.Time     `gorm:"column:revoked_at" json:"revoked_at"`
+	CreatedAt  time.Time      `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
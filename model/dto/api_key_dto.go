@@ -0,0 +1,20 @@
+package dto
+
+import "edu-learn/model"
+
+type CreateAPIKeyDto struct {
+	Name          string   `json:"name" binding:"required,notblank"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"` // kosong => tidak kedaluwarsa
+}
+
+type CreateServiceAccountDto struct {
+	Name string `json:"name" binding:"required,notblank,max=255"`
+	Role string `json:"role" binding:"required,oneof=admin instructor student"`
+}
+
+// APIKeyCreatedResponse => Key asli hanya dikirim sekali di sini, setelah itu hanya prefix yang bisa dilihat
+type APIKeyCreatedResponse struct {
+	model.APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import modelutils "edu-learn/utils/model_utils"
+
+type RefreshTokenDto struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutDto struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ForgotPasswordDto struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordDto struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ResendVerificationDto struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type LoginMFADto struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFATokenDto struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+type MFACodeDto struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFARolePolicyDto struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+type ImpersonateDto struct {
+	Reason string `json:"reason" binding:"required,notblank"`
+}
+
+// MFAConfirmResponse => Token login beserta recovery code yang hanya ditampilkan sekali
+type MFAConfirmResponse struct {
+	modelutils.TokenPair
+	RecoveryCodes []string `json:"recovery_codes"`
+}
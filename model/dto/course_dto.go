@@ -0,0 +1,99 @@
+package dto
+
+import (
+	"edu-learn/model"
+	modelutils "edu-learn/utils/model_utils"
+	"time"
+)
+
+// InstructorID hanya boleh diisi admin, instruktur selalu membuat kursus atas namanya sendiri
+type CourseDto struct {
+	Title        string  `json:"title" binding:"required,notblank,max=255"`
+	Description  string  `json:"description"`
+	Price        float64 `json:"price" binding:"price"`
+	Category     string  `json:"category" binding:"required,course_category"`
+	InstructorID int     `json:"instructor_id" binding:"omitempty,gt=0"`
+}
+
+type CourseResponse struct {
+	ID           int                       `json:"id"`
+	Title        string                    `json:"title"`
+	Description  string                    `json:"description"`
+	InstructorID int                       `json:"instructor_id"`
+	Instructor   *CourseInstructorResponse `json:"instructor,omitempty"`
+	Price        float64                   `json:"price"`
+	Category     string                    `json:"category"`
+	CreatedAt    time.Time                 `json:"created_at"`
+	UpdatedAt    time.Time                 `json:"updated_at"`
+
+	Materials []MaterialSummaryResponse `json:"materials,omitempty"`
+}
+
+// CourseInstructorResponse => Hanya nama instruktur yang ditampilkan di halaman kursus publik
+type CourseInstructorResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// CourseSearchResponse => Kursus hasil pencarian, title_highlight dan snippet sudah di-escape, hanya berisi tag <mark> di kata yang cocok
+type CourseSearchResponse struct {
+	CourseResponse
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+func (c CourseDto) ToModel() model.Course {
+	return model.Course{
+		Title:        c.Title,
+		Description:  c.Description,
+		Price:        c.Price,
+		Category:     c.Category,
+		InstructorID: c.InstructorID,
+	}
+}
+
+func ToCourseResponse(course model.Course) CourseResponse {
+	response := CourseResponse{
+		ID:           course.ID,
+		Title:        course.Title,
+		Description:  course.Description,
+		InstructorID: course.InstructorID,
+		Price:        course.Price,
+		Category:     course.Category,
+		CreatedAt:    course.CreatedAt,
+		UpdatedAt:    course.UpdatedAt,
+	}
+
+	if course.Instructor != nil {
+		response.Instructor = &CourseInstructorResponse{ID: course.Instructor.ID, Name: course.Instructor.Name}
+	}
+
+	// Isi materi hanya untuk peserta, di sini cukup daftar judulnya
+	for _, material := range course.Materials {
+		response.Materials = append(response.Materials, ToMaterialSummaryResponse(material))
+	}
+
+	return response
+}
+
+func ToCourseResponses(courses []model.Course) []CourseResponse {
+	responses := make([]CourseResponse, 0, len(courses))
+	for _, course := range courses {
+		responses = append(responses, ToCourseResponse(course))
+	}
+	return responses
+}
+
+func ToCourseSearchResponses(results []modelutils.CourseSearchResult) []CourseSearchResponse {
+	responses := make([]CourseSearchResponse, 0, len(results))
+	for _, result := range results {
+		responses = append(responses, CourseSearchResponse{
+			CourseResponse: ToCourseResponse(result.Course),
+			Rank:           result.Rank,
+			TitleHighlight: result.TitleHighlight,
+			Snippet:        result.Snippet,
+		})
+	}
+	return responses
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	modelutils "edu-learn/utils/model_utils"
+	"time"
+)
+
+// CourseQueryDto => Query filter dan urutan untuk GET /courses, tanggal memakai format YYYY-MM-DD
+type CourseQueryDto struct {
+	Category     string     `form:"category" json:"category" binding:"omitempty,course_category"`
+	InstructorID int        `form:"instructor_id" json:"instructor_id" binding:"omitempty,gt=0"`
+	MinPrice     *float64   `form:"min_price" json:"min_price" binding:"omitempty,price"`
+	MaxPrice     *float64   `form:"max_price" json:"max_price" binding:"omitempty,price"`
+	Free         *bool      `form:"free" json:"free"`
+	CreatedFrom  *time.Time `form:"created_from" json:"created_from" time_format:"2006-01-02"`
+	CreatedTo    *time.Time `form:"created_to" json:"created_to" time_format:"2006-01-02"`
+	Sort         string     `form:"sort" json:"sort" binding:"omitempty,oneof=newest price_asc price_desc title popularity"`
+}
+
+func (q CourseQueryDto) ToFilter() modelutils.CourseFilter {
+	filter := modelutils.CourseFilter{
+		Category:     q.Category,
+		InstructorID: q.InstructorID,
+		MinPrice:     q.MinPrice,
+		MaxPrice:     q.MaxPrice,
+		Free:         q.Free,
+		CreatedFrom:  q.CreatedFrom,
+		CreatedTo:    q.CreatedTo,
+		Sort:         q.Sort,
+	}
+	if filter.Sort == "" {
+		filter.Sort = modelutils.CourseSortNewest
+	}
+
+	return filter
+}
+
+// CourseSearchDto => Query ?q= untuk GET /courses/search
+type CourseSearchDto struct {
+	Query string `form:"q" json:"q" binding:"required,notblank,max=100"`
+}
@@ -1,6 +1,22 @@
 package dto
 
-type EnrollmentDto struct {
-	StudentID int `gorm:"column:student_id;not null"`
-	CourseID  int `gorm:"column:course_id;not null"`
+import (
+	"edu-learn/model"
+	"time"
+)
+
+type EnrollmentResponse struct {
+	ID         int       `json:"id"`
+	StudentID  int       `json:"student_id"`
+	CourseID   int       `json:"course_id"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
+
+func ToEnrollmentResponse(enrollment model.Enrollment) EnrollmentResponse {
+	return EnrollmentResponse{
+		ID:         enrollment.ID,
+		StudentID:  enrollment.StudentID,
+		CourseID:   enrollment.CourseID,
+		EnrolledAt: enrollment.EnrolledAt,
+	}
 }
@@ -0,0 +1,10 @@
+package dto
+
+type InstructorApplicationDto struct {
+	Bio         string `json:"bio" binding:"required,notblank"`
+	Credentials string `json:"credentials" binding:"required,notblank"`
+}
+
+type ReviewApplicationDto struct {
+	Notes string `json:"notes"`
+}
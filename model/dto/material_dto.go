@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"edu-learn/model"
+	"time"
+)
+
+type MaterialDto struct {
+	Title   string `json:"title" binding:"required,notblank,max=255"`
+	Content string `json:"content"`
+	FileURL string `json:"file_url" binding:"omitempty,http_url,max=255"`
+}
+
+type MaterialResponse struct {
+	ID        int       `json:"id"`
+	CourseID  int       `json:"course_id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	FileURL   string    `json:"file_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MaterialSummaryResponse struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+func (m MaterialDto) ToModel() model.Material {
+	return model.Material{Title: m.Title, Content: m.Content, FileURL: m.FileURL}
+}
+
+func ToMaterialResponse(material model.Material) MaterialResponse {
+	return MaterialResponse{
+		ID:        material.ID,
+		CourseID:  material.CourseID,
+		Title:     material.Title,
+		Content:   material.Content,
+		FileURL:   material.FileURL,
+		CreatedAt: material.CreatedAt,
+	}
+}
+
+func ToMaterialResponses(materials []model.Material) []MaterialResponse {
+	responses := make([]MaterialResponse, 0, len(materials))
+	for _, material := range materials {
+		responses = append(responses, ToMaterialResponse(material))
+	}
+	return responses
+}
+
+func ToMaterialSummaryResponse(material model.Material) MaterialSummaryResponse {
+	return MaterialSummaryResponse{ID: material.ID, Title: material.Title}
+}
@@ -0,0 +1,21 @@
+package dto
+
+import modelutils "edu-learn/utils/model_utils"
+
+// PageDto => Query ?page=&per_page= untuk semua endpoint list
+type PageDto struct {
+	Page    int `form:"page" json:"page" binding:"omitempty,min=1"`
+	PerPage int `form:"per_page" json:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+func (p PageDto) ToPageQuery() modelutils.PageQuery {
+	page := modelutils.PageQuery{Page: p.Page, PerPage: p.PerPage}
+	if page.Page == 0 {
+		page.Page = 1
+	}
+	if page.PerPage == 0 {
+		page.PerPage = modelutils.DefaultPerPage
+	}
+
+	return page
+}
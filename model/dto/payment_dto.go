@@ -0,0 +1,108 @@
+package dto
+
+import (
+	"edu-learn/model"
+	"time"
+)
+
+type PaymentDto struct {
+	CourseID int     `json:"course_id" binding:"required,gt=0"`
+	Amount   float64 `json:"amount" binding:"price"`
+}
+
+type RefundDto struct {
+	Amount float64 `json:"amount" binding:"price"` // kosong = refund penuh sisa pembayaran
+	Reason string  `json:"reason" binding:"required,notblank"`
+}
+
+type PaymentResponse struct {
+	ID          int                    `json:"id"`
+	StudentID   int                    `json:"student_id"`
+	CourseID    int                    `json:"course_id"`
+	Course      *PaymentCourseResponse `json:"course,omitempty"`
+	Amount      float64                `json:"amount"`
+	Status      string                 `json:"status"`
+	Gateway     string                 `json:"gateway"`
+	GatewayRef  string                 `json:"gateway_ref"`
+	PaymentURL  string                 `json:"payment_url"`
+	PaymentDate time.Time              `json:"payment_date"`
+	CompletedAt *time.Time             `json:"completed_at"`
+}
+
+// PaymentCourseResponse => Ringkasan kursus yang dibayar, tanpa materi dan data instruktur
+type PaymentCourseResponse struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type RefundResponse struct {
+	ID        int                  `json:"id"`
+	PaymentID int                  `json:"payment_id"`
+	Amount    float64              `json:"amount"`
+	Reason    string               `json:"reason"`
+	Status    string               `json:"status"`
+	ActorID   *int                 `json:"actor_id"`
+	Actor     *RefundActorResponse `json:"actor,omitempty"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// RefundActorResponse => Hanya nama admin/instruktur yang melakukan refund
+type RefundActorResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func ToPaymentResponse(payment model.Payment) PaymentResponse {
+	response := PaymentResponse{
+		ID:          payment.ID,
+		StudentID:   payment.StudentID,
+		CourseID:    payment.CourseID,
+		Amount:      payment.Amount,
+		Status:      payment.Status,
+		Gateway:     payment.Gateway,
+		GatewayRef:  payment.GatewayRef,
+		PaymentURL:  payment.PaymentURL,
+		PaymentDate: payment.PaymentDate,
+		CompletedAt: payment.CompletedAt,
+	}
+
+	if payment.Course != nil {
+		response.Course = &PaymentCourseResponse{ID: payment.Course.ID, Title: payment.Course.Title}
+	}
+
+	return response
+}
+
+func ToPaymentResponses(payments []model.Payment) []PaymentResponse {
+	responses := make([]PaymentResponse, 0, len(payments))
+	for _, payment := range payments {
+		responses = append(responses, ToPaymentResponse(payment))
+	}
+	return responses
+}
+
+func ToRefundResponse(refund model.Refund) RefundResponse {
+	response := RefundResponse{
+		ID:        refund.ID,
+		PaymentID: refund.PaymentID,
+		Amount:    refund.Amount,
+		Reason:    refund.Reason,
+		Status:    refund.Status,
+		ActorID:   refund.ActorID,
+		CreatedAt: refund.CreatedAt,
+	}
+
+	if refund.Actor != nil {
+		response.Actor = &RefundActorResponse{ID: refund.Actor.ID, Name: refund.Actor.Name}
+	}
+
+	return response
+}
+
+func ToRefundResponses(refunds []model.Refund) []RefundResponse {
+	responses := make([]RefundResponse, 0, len(refunds))
+	for _, refund := range refunds {
+		responses = append(responses, ToRefundResponse(refund))
+	}
+	return responses
+}
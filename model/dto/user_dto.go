@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"edu-learn/model"
+	"time"
+)
+
+// Role tidak bisa diisi saat registrasi, selalu student
+type RegisterDto struct {
+	Name     string `json:"name" binding:"required,notblank,max=255"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	Password string `json:"password" binding:"required"` // aturan password dicek password policy
+}
+
+type LoginDto struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// Field kosong tidak diubah, password kosong => password lama tetap dipakai
+type UpdateUserDto struct {
+	Name     string `json:"name" binding:"omitempty,notblank,max=255"`
+	Email    string `json:"email" binding:"omitempty,email,max=255"`
+	Password string `json:"password"`
+}
+
+// UserResponse => Data user yang boleh keluar dari API, tanpa hash password dan relasi
+type UserResponse struct {
+	ID              int        `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	AccountType     string     `json:"account_type"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (r RegisterDto) ToModel() model.User {
+	return model.User{Name: r.Name, Email: r.Email, Password: r.Password}
+}
+
+func (r UpdateUserDto) ToModel() model.User {
+	return model.User{Name: r.Name, Email: r.Email, Password: r.Password}
+}
+
+func ToUserResponse(user model.User) UserResponse {
+	return UserResponse{
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		Role:            user.Role,
+		AccountType:     user.AccountType,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
+func ToUserResponses(users []model.User) []UserResponse {
+	responses := make([]UserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, ToUserResponse(user))
+	}
+	return responses
+}
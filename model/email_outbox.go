@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSending = "sending"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+)
+
+// Email tidak dikirim langsung, tapi diantrekan lalu dikirim oleh worker outbox
+type EmailOutbox struct {
+	ID        int        `gorm:"primaryKey;autoIncrement"`
+	Recipient string     `gorm:"type:varchar(255);not null"`
+	Subject   string     `gorm:"type:varchar(255);not null"`
+	Body      string     `gorm:"type:text;not null"`
+	Status    string     `gorm:"type:varchar(20);not null;default:pending;check:status IN ('pending', 'sending', 'sent', 'failed')"`
+	Attempts  int        `gorm:"not null;default:0"`
+	LastError string     `gorm:"column:last_error;type:text" json:"last_error"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+	ClaimedAt *time.Time `gorm:"column:claimed_at" json:"claimed_at"` // diisi saat worker mengambil email untuk dikirim
+	SentAt    *time.Time `gorm:"column:sent_at" json:"sent_at"`
+}
+
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
@@ -4,9 +4,9 @@ import "time"
 
 type Enrollment struct {
 	ID         int       `gorm:"primaryKey;autoIncrement"`
-	StudentID  int       `gorm:"column:student_id;not null"`
+	StudentID  int       `gorm:"column:student_id;not null;uniqueIndex:idx_enrollments_student_course"`
 	Student    *User     `gorm:"foreignKey:StudentID;constraint:OnDelete:CASCADE"`
-	CourseID   int       `gorm:"column:course_id;not null"`
+	CourseID   int       `gorm:"column:course_id;not null;uniqueIndex:idx_enrollments_student_course"`
 	Course     *Course   `gorm:"foreignKey:CourseID;constraint:OnDelete:CASCADE"`
 	EnrolledAt time.Time `gorm:"column:enrolled_at;autoCreateTime"`
 }
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Menyimpan response dari request POST yang memakai header Idempotency-Key
+type IdempotencyKey struct {
+	ID           int       `gorm:"primaryKey;autoIncrement"`
+	Key          string    `gorm:"column:idempotency_key;type:varchar(255);not null;uniqueIndex:idx_idempotency_user_key"`
+	UserID       int       `gorm:"column:user_id;not null;uniqueIndex:idx_idempotency_user_key" json:"user_id"`
+	User         *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Method       string    `gorm:"type:varchar(10);not null"`
+	Path         string    `gorm:"type:varchar(255);not null"`
+	RequestHash  string    `gorm:"column:request_hash;type:varchar(64);not null" json:"request_hash"`
+	StatusCode   int       `gorm:"column:status_code;default:0" json:"status_code"` // 0 = request masih diproses
+	ResponseBody []byte    `gorm:"column:response_body;type:bytea" json:"response_body"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Catatan setiap request yang dilakukan admin saat login sebagai user lain
+type ImpersonationAuditLog struct {
+	ID             int       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TokenID        string    `gorm:"column:token_id;type:varchar(64);not null;index" json:"token_id"` // jti token impersonation
+	ImpersonatorID *int      `gorm:"column:impersonator_id" json:"impersonator_id"`
+	Impersonator   *User     `gorm:"foreignKey:ImpersonatorID;constraint:OnDelete:SET NULL" json:"-"`
+	UserID         *int      `gorm:"column:user_id" json:"user_id"`
+	User           *User     `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"-"`
+	Method         string    `gorm:"type:varchar(10);not null" json:"method"`
+	Path           string    `gorm:"type:text;not null" json:"path"`
+	StatusCode     int       `gorm:"column:status_code;not null" json:"status_code"`
+	Reason         string    `gorm:"type:text" json:"reason,omitempty"` // hanya diisi saat impersonation dimulai
+	IPAddress      string    `gorm:"column:ip_address;type:varchar(64)" json:"ip_address"`
+	UserAgent      string    `gorm:"column:user_agent;type:text" json:"user_agent"`
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
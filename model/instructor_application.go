@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+const (
+	ApplicationStatusPending  = "pending"
+	ApplicationStatusApproved = "approved"
+	ApplicationStatusRejected = "rejected"
+)
+
+// Pengajuan siswa untuk menjadi instruktur, direview oleh admin
+type InstructorApplication struct {
+	ID          int        `gorm:"primaryKey;autoIncrement"`
+	UserID      int        `gorm:"column:user_id;not null;index" json:"user_id"`
+	User        *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Bio         string     `gorm:"type:text;not null"`
+	Credentials string     `gorm:"type:text;not null"`
+	Status      string     `gorm:"type:varchar(20);not null;default:'pending';check:status IN ('pending', 'approved', 'rejected')"`
+	ReviewerID  *int       `gorm:"column:reviewer_id" json:"reviewer_id"`
+	Reviewer    *User      `gorm:"foreignKey:ReviewerID;constraint:OnDelete:SET NULL" json:"-"`
+	ReviewNotes string     `gorm:"column:review_notes;type:text" json:"review_notes"`
+	ReviewedAt  *time.Time `gorm:"column:reviewed_at" json:"reviewed_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
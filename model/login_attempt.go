@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Hasil percobaan login yang dicatat di login_history
+const (
+	LoginOutcomeSuccess    = "success"
+	LoginOutcomeFailed     = "failed"
+	LoginOutcomeLocked     = "locked"
+	LoginOutcomeUnverified = "unverified"
+	LoginOutcomeMFAPending = "mfa_pending"
+	LoginOutcomeMFAFailed  = "mfa_failed"
+)
+
+// Penghitung login gagal per akun (account:<email>) atau per IP (ip:<alamat>)
+type LoginAttempt struct {
+	Key          string     `gorm:"column:attempt_key;type:varchar(320);primaryKey" json:"key"`
+	FailedCount  int        `gorm:"column:failed_count;not null;default:0" json:"failed_count"`
+	LastFailedAt time.Time  `gorm:"column:last_failed_at;not null" json:"last_failed_at"`
+	LockedUntil  *time.Time `gorm:"column:locked_until" json:"locked_until"`
+}
+
+// Riwayat login, UserID kosong jika email tidak terdaftar
+type LoginHistory struct {
+	ID        int       `gorm:"primaryKey;autoIncrement"`
+	UserID    *int      `gorm:"column:user_id;index" json:"user_id"`
+	User      *User     `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"-"`
+	Email     string    `gorm:"column:email;type:varchar(255);not null" json:"email"`
+	IPAddress string    `gorm:"column:ip_address;type:varchar(64)" json:"ip_address"`
+	UserAgent string    `gorm:"column:user_agent;type:text" json:"user_agent"`
+	Outcome   string    `gorm:"column:outcome;type:varchar(20);not null;check:outcome IN ('success', 'failed', 'locked', 'unverified', 'mfa_pending', 'mfa_failed')" json:"outcome"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (LoginHistory) TableName() string {
+	return "login_history"
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Secret TOTP user, aktif setelah ConfirmedAt terisi
+type UserMFA struct {
+	UserID       int        `gorm:"column:user_id;primaryKey" json:"user_id"`
+	User         *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Secret       string     `gorm:"column:secret;type:text;not null" json:"-"` // terenkripsi AES-GCM
+	ConfirmedAt  *time.Time `gorm:"column:confirmed_at" json:"confirmed_at"`
+	LastUsedStep int64      `gorm:"column:last_used_step;not null;default:0" json:"-"` // mencegah kode TOTP dipakai ulang
+	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// Recovery code hanya disimpan dalam bentuk hash dan hanya bisa dipakai sekali
+type MFARecoveryCode struct {
+	ID        int        `gorm:"primaryKey;autoIncrement"`
+	UserID    int        `gorm:"column:user_id;not null;index" json:"user_id"`
+	User      *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	CodeHash  string     `gorm:"column:code_hash;type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+// Role yang wajib memakai MFA, diatur oleh admin
+type MFARolePolicy struct {
+	Role      string    `gorm:"column:role;type:varchar(50);primaryKey;check:role IN ('student', 'instructor', 'admin')" json:"role"`
+	Required  bool      `gorm:"column:required;not null;default:false" json:"required"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
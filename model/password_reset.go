@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Token reset password hanya disimpan dalam bentuk hash dan hanya bisa dipakai sekali
+type PasswordResetToken struct {
+	ID        int        `gorm:"primaryKey;autoIncrement"`
+	UserID    int        `gorm:"column:user_id;not null" json:"user_id"`
+	User      *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	TokenHash string     `gorm:"column:token_hash;type:varchar(64);not null;unique" json:"-"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
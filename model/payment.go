@@ -2,13 +2,50 @@ package model
 
 import "time"
 
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusCompleted = "completed"
+	PaymentStatusFailed    = "failed"
+	PaymentStatusRefunded  = "refunded"
+)
+
+// paymentTransitions => Perubahan status pembayaran yang diizinkan
+var paymentTransitions = map[string][]string{
+	PaymentStatusPending:   {PaymentStatusCompleted, PaymentStatusFailed},
+	PaymentStatusCompleted: {PaymentStatusRefunded},
+}
+
 type Payment struct {
-	ID          int       `gorm:"primaryKey;autoIncrement"`
-	StudentID   int       `gorm:"column:student_id;not null"`
-	Student     *User     `gorm:"foreignKey:StudentID;constraint:OnDelete:CASCADE"`
-	CourseID    int       `gorm:"column:course_id;not null"`
-	Course      *Course   `gorm:"foreignKey:CourseID;constraint:OnDelete:CASCADE"`
-	Amount      float64   `gorm:"type:decimal(10,2);not null"`
-	Status      string    `gorm:"type:varchar(50);not null;check:status IN ('pending', 'completed', 'failed')"`
-	PaymentDate time.Time `gorm:"column:payment_date;autoCreateTime"`
+	ID          int        `gorm:"primaryKey;autoIncrement"`
+	StudentID   int        `gorm:"column:student_id;not null"`
+	Student     *User      `gorm:"foreignKey:StudentID;constraint:OnDelete:CASCADE"`
+	CourseID    int        `gorm:"column:course_id;not null"`
+	Course      *Course    `gorm:"foreignKey:CourseID;constraint:OnDelete:CASCADE"`
+	Amount      float64    `gorm:"type:decimal(10,2);not null"`
+	Status      string     `gorm:"type:varchar(50);not null;check:status IN ('pending', 'completed', 'failed', 'refunded')"`
+	Gateway     string     `gorm:"type:varchar(50)"`
+	GatewayRef  string     `gorm:"column:gateway_ref;type:varchar(255)" json:"gateway_ref"`
+	PaymentURL  string     `gorm:"column:payment_url;type:varchar(255)" json:"payment_url"`
+	PaymentDate time.Time  `gorm:"column:payment_date;autoCreateTime"`
+	CompletedAt *time.Time `gorm:"column:completed_at" json:"completed_at"`
+}
+
+// CanTransitionTo => Cek apakah status pembayaran boleh berubah ke status baru
+func (p Payment) CanTransitionTo(status string) bool {
+	for _, next := range paymentTransitions[p.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Event webhook dari payment gateway, event_id unik untuk mencegah proses ganda
+type PaymentEvent struct {
+	ID         int       `gorm:"primaryKey;autoIncrement"`
+	EventID    string    `gorm:"column:event_id;type:varchar(255);not null;unique" json:"event_id"`
+	PaymentID  int       `gorm:"column:payment_id;not null" json:"payment_id"`
+	Payment    *Payment  `gorm:"foreignKey:PaymentID;constraint:OnDelete:CASCADE"`
+	Status     string    `gorm:"type:varchar(50);not null"`
+	ReceivedAt time.Time `gorm:"column:received_at;autoCreateTime" json:"received_at"`
 }
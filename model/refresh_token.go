@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Refresh token disimpan dalam bentuk hash, satu family = satu rangkaian rotasi dari login yang sama
+type RefreshToken struct {
+	ID        int        `gorm:"primaryKey;autoIncrement"`
+	UserID    int        `gorm:"column:user_id;not null" json:"user_id"`
+	User      *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	FamilyID  string     `gorm:"column:family_id;type:varchar(64);not null;index" json:"family_id"`
+	TokenHash string     `gorm:"column:token_hash;type:varchar(64);not null;unique" json:"-"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at"`
+	RevokedAt *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
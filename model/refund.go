@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusCompleted = "completed"
+	RefundStatusFailed    = "failed"
+)
+
+// Refund pending sudah memotong sisa saldo sebelum gateway dipanggil, supaya refund paralel tidak melebihi pembayaran
+type Refund struct {
+	ID        int       `gorm:"primaryKey;autoIncrement"`
+	PaymentID int       `gorm:"column:payment_id;not null" json:"payment_id"`
+	Payment   *Payment  `gorm:"foreignKey:PaymentID;constraint:OnDelete:CASCADE"`
+	Amount    float64   `gorm:"type:decimal(10,2);not null"`
+	Reason    string    `gorm:"type:text;not null"`
+	Status    string    `gorm:"type:varchar(20);not null;default:pending;check:status IN ('pending', 'completed', 'failed')"`
+	ActorID   *int      `gorm:"column:actor_id" json:"actor_id"` // null jika akun admin/instruktur sudah dihapus
+	Actor     *User     `gorm:"foreignKey:ActorID;constraint:OnDelete:SET NULL"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
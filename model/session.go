@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Sesi login, ID sama dengan family_id refresh token dan dibawa access token sebagai claim sid
+type Session struct {
+	ID         string     `gorm:"type:varchar(64);primaryKey" json:"id"`
+	UserID     int        `gorm:"column:user_id;not null;index" json:"user_id"`
+	User       *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	IPAddress  string     `gorm:"column:ip_address;type:varchar(64)" json:"ip_address"`
+	UserAgent  string     `gorm:"column:user_agent;type:text" json:"user_agent"`
+	LastSeenAt time.Time  `gorm:"column:last_seen_at;not null" json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;not null" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+
+	Current bool `gorm:"-" json:"current"` // sesi yang dipakai request ini
+}
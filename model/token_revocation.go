@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Access token (jti) yang dicabut sebelum masa berlakunya habis
+type RevokedToken struct {
+	JTI       string    `gorm:"column:jti;type:varchar(64);primaryKey" json:"jti"`
+	UserID    int       `gorm:"column:user_id;not null" json:"user_id"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null;index" json:"expires_at"`
+	RevokedAt time.Time `gorm:"column:revoked_at;autoCreateTime" json:"revoked_at"`
+}
+
+// Semua access token user yang diterbitkan sebelum RevokedAt dianggap tidak berlaku.
+// Tidak memakai foreign key supaya tetap ada setelah user dihapus.
+type UserTokenRevocation struct {
+	UserID    int       `gorm:"column:user_id;primaryKey" json:"user_id"`
+	RevokedAt time.Time `gorm:"column:revoked_at;not null" json:"revoked_at"`
+}
@@ -2,16 +2,25 @@ package model
 
 import "time"
 
+// Jenis akun, service account hanya bisa dipakai lewat API key
+const (
+	AccountTypeUser    = "user"
+	AccountTypeService = "service"
+)
+
 // Dapat diubah jika user login dengan email
 type User struct {
 	ID        int       `gorm:"primaryKey;autoIncrement"`
 	Name      string    `gorm:"type:varchar(255);not null"`
 	Email     string    `gorm:"type:varchar(255);unique;not null"`
-	Password  string    `gorm:"type:varchar(255);not null"`
+	Password  string    `gorm:"type:varchar(255);not null" json:"-"` // hash bcrypt, tidak pernah dikirim ke client
 	Role      string    `gorm:"type:varchar(50);not null;check:role IN ('student', 'instructor', 'admin')"`
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
 
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at"` // nil => email belum diverifikasi
+	AccountType     string     `gorm:"column:account_type;type:varchar(20);not null;default:user;check:account_type IN ('user', 'service')"`
+
 	Courses     []Course     `gorm:"foreignKey:InstructorID;constraint:OnDelete:CASCADE"`
 	Enrollments []Enrollment `gorm:"foreignKey:StudentID;constraint:OnDelete:CASCADE"`
 	Payments    []Payment    `gorm:"foreignKey:StudentID;constraint:OnDelete:CASCADE"`
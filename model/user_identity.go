@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Identitas dari provider OIDC, satu user bisa punya beberapa identitas
+type UserIdentity struct {
+	ID          int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      int        `gorm:"column:user_id;not null;index" json:"user_id"`
+	User        *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Provider    string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject" json:"subject"`
+	Email       string     `gorm:"type:varchar(255)" json:"email"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at" json:"last_login_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+// State login OIDC, menyimpan code_verifier PKCE dan nonce sampai callback datang
+type OIDCState struct {
+	StateHash    string    `gorm:"column:state_hash;type:varchar(64);primaryKey" json:"-"`
+	CodeVerifier string    `gorm:"column:code_verifier;type:varchar(128);not null" json:"-"`
+	Nonce        string    `gorm:"type:varchar(64);not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"column:expires_at;not null" json:"expires_at"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (OIDCState) TableName() string {
+	return "oidc_states"
+}
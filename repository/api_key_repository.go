@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(key *model.APIKey) (model.APIKey, error)
+	GetAPIKeyById(id int) (model.APIKey, error)
+	GetAPIKeyByPrefix(prefix string) (model.APIKey, error)
+	GetAPIKeysByUserID(userID int, page modelutils.PageQuery) ([]model.APIKey, int64, error)
+	RevokeAPIKey(id int) error
+	TouchAPIKey(id int, usedAt time.Time) error
+}
+
+func (a *apiKeyRepository) CreateAPIKey(key *model.APIKey) (model.APIKey, error) {
+	err := a.db.Create(key).Error
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return *key, nil
+}
+
+func (a *apiKeyRepository) GetAPIKeyById(id int) (model.APIKey, error) {
+	var key model.APIKey
+
+	err := a.db.First(&key, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.APIKey{}, apperror.NotFound("api key not found")
+	}
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByPrefix => User ikut diambil supaya role selalu sesuai data terbaru
+func (a *apiKeyRepository) GetAPIKeyByPrefix(prefix string) (model.APIKey, error) {
+	var key model.APIKey
+
+	err := a.db.
+		Preload("User").
+		Where("prefix = ?", prefix).
+		First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.APIKey{}, apperror.NotFound("api key not found")
+	}
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (a *apiKeyRepository) GetAPIKeysByUserID(userID int, page modelutils.PageQuery) ([]model.APIKey, int64, error) {
+	keys := []model.APIKey{}
+	var total int64
+
+	query := a.db.
+		Model(&model.APIKey{}).
+		Where("user_id = ?", userID).
+		Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+
+	if total == 0 {
+		return keys, 0, nil
+	}
+
+	err = query.
+		Order("created_at DESC").
+		Scopes(paginate(page)).
+		Find(&keys).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get api keys: %w", err)
+	}
+
+	return keys, total, nil
+}
+
+func (a *apiKeyRepository) RevokeAPIKey(id int) error {
+	result := a.db.
+		Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke api key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperror.Conflict("api key already revoked")
+	}
+
+	return nil
+}
+
+// TouchAPIKey => last_used_at cukup presisi menit supaya tidak menulis ke database di setiap request
+func (a *apiKeyRepository) TouchAPIKey(id int, usedAt time.Time) error {
+	err := a.db.
+		Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, usedAt.Add(-time.Minute)).
+		UpdateColumn("last_used_at", usedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to update api key: %w", err)
+	}
+
+	return nil
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
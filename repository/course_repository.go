@@ -2,8 +2,12 @@ package repository
 
 import (
 	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
 	"errors"
 	"fmt"
+	"strings"
+	"unicode"
 
 	"gorm.io/gorm"
 )
@@ -14,15 +18,40 @@ type courseRepository struct {
 
 type CourseRepository interface {
 	CreateCourse(course *model.Course) (model.Course, error)
-	GetAllCourse() ([]model.Course, error)
+	GetAllCourse(filter modelutils.CourseFilter, page modelutils.PageQuery) ([]model.Course, int64, error)
 	GetCourseById(id int) (model.Course, error)
 	UpdateCourse(id int, course *model.Course) (model.Course, error)
 	DeleteCourse(id int) error
-	GetCoursesByUserID(userID int) ([]model.Course, error)
+	GetCoursesByUserID(userID int, page modelutils.PageQuery) ([]model.Course, int64, error)
+	SearchCourses(text string, page modelutils.PageQuery) ([]modelutils.CourseSearchResult, int64, error)
+}
+
+// searchSimilarityThreshold => Batas word_similarity judul untuk toleransi typo, default pg_trgm (0.6) terlalu ketat untuk satu kata yang salah ketik
+const searchSimilarityThreshold = 0.4
+
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter= ... "
+
+const searchTitleHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+
+// escapeHTMLSQL => Escape &, <, dan > di SQL sebelum ts_headline, supaya HTML dari judul atau deskripsi
+// tidak ikut dikirim mentah dan satu-satunya tag di hasil adalah <mark> yang ditambahkan ts_headline
+func escapeHTMLSQL(expr string) string {
+	return "replace(replace(replace(" + expr + ", '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+}
+
+// courseSearchHit => Baris hasil query pencarian sebelum data kursus lengkap dimuat
+type courseSearchHit struct {
+	ID             int
+	SearchRank     float64
+	TitleHighlight string
+	Snippet        string
 }
 
 func (c *courseRepository) CreateCourse(course *model.Course) (model.Course, error) {
 	err := c.db.Create(course).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return model.Course{}, apperror.Conflict("course title already exists")
+	}
 	if err != nil {
 		return model.Course{}, fmt.Errorf("failed to create course: %w", err)
 	}
@@ -30,22 +59,31 @@ func (c *courseRepository) CreateCourse(course *model.Course) (model.Course, err
 	return *course, nil
 }
 
-func (c *courseRepository) GetAllCourse() ([]model.Course, error) {
-	var courses []model.Course
+// GetAllCourse => List publik tanpa materi, list kosong bukan error
+func (c *courseRepository) GetAllCourse(filter modelutils.CourseFilter, page modelutils.PageQuery) ([]model.Course, int64, error) {
+	courses := []model.Course{}
+	var total int64
 
-	err := c.db.
-		Preload("Instructor").
-		Preload("Materials").
-		Find(&courses).Error
+	query := c.db.Model(&model.Course{}).Scopes(filterCourses(filter)).Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to get courses: %w", err)
+		return nil, 0, fmt.Errorf("failed to count courses: %w", err)
+	}
+
+	if total == 0 {
+		return courses, 0, nil
 	}
 
-	if len(courses) == 0 {
-		return nil, fmt.Errorf("no courses found")
+	err = query.
+		Preload("Instructor").
+		Scopes(sortCourses(filter.Sort), paginate(page)).
+		Find(&courses).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get courses: %w", err)
 	}
 
-	return courses, nil
+	return courses, total, nil
 }
 
 func (c *courseRepository) GetCourseById(id int) (model.Course, error) {
@@ -56,7 +94,7 @@ func (c *courseRepository) GetCourseById(id int) (model.Course, error) {
 		Preload("Materials").
 		First(&course, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return model.Course{}, fmt.Errorf("course not found")
+		return model.Course{}, apperror.NotFound("course not found")
 	}
 	if err != nil {
 		return model.Course{}, fmt.Errorf("failed to get course: %w", err)
@@ -70,7 +108,7 @@ func (c *courseRepository) UpdateCourse(id int, course *model.Course) (model.Cou
 
 	err := c.db.First(&existingCourse, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return model.Course{}, fmt.Errorf("course not found")
+		return model.Course{}, apperror.NotFound("course not found")
 	}
 	if err != nil {
 		return model.Course{}, fmt.Errorf("failed to get course: %w", err)
@@ -79,6 +117,9 @@ func (c *courseRepository) UpdateCourse(id int, course *model.Course) (model.Cou
 	err = c.db.
 		Model(&existingCourse).
 		Updates(course).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return model.Course{}, apperror.Conflict("course title already exists")
+	}
 	if err != nil {
 		return model.Course{}, fmt.Errorf("failed to update course: %w", err)
 	}
@@ -91,7 +132,7 @@ func (c *courseRepository) DeleteCourse(id int) error {
 
 	err := c.db.First(&course, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return fmt.Errorf("course not found")
+		return apperror.NotFound("course not found")
 	}
 	if err != nil {
 		return fmt.Errorf("failed to get course: %w", err)
@@ -105,22 +146,191 @@ func (c *courseRepository) DeleteCourse(id int) error {
 	return nil
 }
 
-func (c *courseRepository) GetCoursesByUserID(userID int) ([]model.Course, error) {
-	var courses []model.Course
+func (c *courseRepository) GetCoursesByUserID(userID int, page modelutils.PageQuery) ([]model.Course, int64, error) {
+	courses := []model.Course{}
+	var total int64
 
-	err := c.db.
+	query := c.db.
+		Model(&model.Course{}).
 		Joins("JOIN enrollments ON enrollments.course_id = courses.id").
 		Where("enrollments.student_id = ?", userID).
+		Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count courses: %w", err)
+	}
+
+	if total == 0 {
+		return courses, 0, nil
+	}
+
+	err = query.
+		Order("enrollments.enrolled_at DESC").
+		Scopes(paginate(page)).
 		Find(&courses).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to get courses: %w", err)
+		return nil, 0, fmt.Errorf("failed to get courses: %w", err)
 	}
 
-	if len(courses) == 0 {
-		return nil, fmt.Errorf("no courses found")
+	return courses, total, nil
+}
+
+// SearchCourses => Full-text search di courses.search_vector (judul, kategori, deskripsi, judul materi)
+// dengan prefix matching per kata, ditambah kemiripan trigram judul untuk kata yang salah ketik
+func (c *courseRepository) SearchCourses(text string, page modelutils.PageQuery) ([]modelutils.CourseSearchResult, int64, error) {
+	results := []modelutils.CourseSearchResult{}
+	var total int64
+
+	tsQuery := prefixTSQuery(text)
+	if tsQuery == "" {
+		return results, 0, nil
 	}
 
-	return courses, nil
+	var hits []courseSearchHit
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		// SET LOCAL tidak menerima parameter dan hanya berlaku sampai transaksi selesai
+		err := tx.Exec(fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %.2f", searchSimilarityThreshold)).Error
+		if err != nil {
+			return fmt.Errorf("failed to set similarity threshold: %w", err)
+		}
+
+		query := tx.
+			Model(&model.Course{}).
+			Where("courses.search_vector @@ to_tsquery('simple', ?) OR ? <% courses.title", tsQuery, text).
+			Session(&gorm.Session{})
+
+		err = query.Count(&total).Error
+		if err != nil {
+			return fmt.Errorf("failed to count courses: %w", err)
+		}
+
+		if total == 0 {
+			return nil
+		}
+
+		err = query.
+			Select(
+				"courses.id, "+
+					"ts_rank(courses.search_vector, to_tsquery('simple', ?)) + word_similarity(?, courses.title) AS search_rank, "+
+					"ts_headline('simple', "+escapeHTMLSQL("courses.title")+", to_tsquery('simple', ?), ?) AS title_highlight, "+
+					"ts_headline('simple', "+escapeHTMLSQL("coalesce(courses.description, '')")+", to_tsquery('simple', ?), ?) AS snippet",
+				tsQuery, text, tsQuery, searchTitleHeadlineOptions, tsQuery, searchHeadlineOptions,
+			).
+			Order("search_rank DESC, courses.id DESC").
+			Scopes(paginate(page)).
+			Scan(&hits).Error
+		if err != nil {
+			return fmt.Errorf("failed to search courses: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(hits) == 0 {
+		return results, total, nil
+	}
+
+	ids := make([]int, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+
+	var courses []model.Course
+	err = c.db.Preload("Instructor").Where("id IN ?", ids).Find(&courses).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get courses: %w", err)
+	}
+
+	coursesByID := make(map[int]model.Course, len(courses))
+	for _, course := range courses {
+		coursesByID[course.ID] = course
+	}
+
+	// Urutan mengikuti ranking, kursus yang terhapus di antara dua query dilewati
+	for _, hit := range hits {
+		course, ok := coursesByID[hit.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, modelutils.CourseSearchResult{
+			Course:         course,
+			Rank:           hit.SearchRank,
+			TitleHighlight: hit.TitleHighlight,
+			Snippet:        hit.Snippet,
+		})
+	}
+
+	return results, total, nil
+}
+
+// prefixTSQuery => "belajar gola" menjadi "belajar:* & gola:*", hanya huruf dan angka yang dipakai supaya aman untuk to_tsquery
+func prefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+func filterCourses(filter modelutils.CourseFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.Category != "" {
+			db = db.Where("courses.category = ?", filter.Category)
+		}
+		if filter.InstructorID != 0 {
+			db = db.Where("courses.instructor_id = ?", filter.InstructorID)
+		}
+		if filter.MinPrice != nil {
+			db = db.Where("courses.price >= ?", *filter.MinPrice)
+		}
+		if filter.MaxPrice != nil {
+			db = db.Where("courses.price <= ?", *filter.MaxPrice)
+		}
+		if filter.Free != nil && *filter.Free {
+			db = db.Where("courses.price = 0")
+		}
+		if filter.Free != nil && !*filter.Free {
+			db = db.Where("courses.price > 0")
+		}
+		if filter.CreatedFrom != nil {
+			db = db.Where("courses.created_at >= ?", *filter.CreatedFrom)
+		}
+		if filter.CreatedTo != nil {
+			db = db.Where("courses.created_at < ?", filter.CreatedTo.AddDate(0, 0, 1))
+		}
+		return db
+	}
+}
+
+// sortCourses => id selalu jadi urutan terakhir supaya halaman tidak bergeser untuk nilai yang sama
+func sortCourses(sort string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch sort {
+		case modelutils.CourseSortPriceAsc:
+			return db.Order("courses.price ASC, courses.id ASC")
+		case modelutils.CourseSortPriceDesc:
+			return db.Order("courses.price DESC, courses.id DESC")
+		case modelutils.CourseSortTitle:
+			return db.Order("courses.title ASC, courses.id ASC")
+		case modelutils.CourseSortPopularity:
+			// Popularitas = jumlah enrollment, kursus tanpa peserta tetap ikut dengan nilai 0
+			return db.
+				Select("courses.*").
+				Joins("LEFT JOIN (SELECT course_id, COUNT(*) AS enrollment_count FROM enrollments GROUP BY course_id) popularity ON popularity.course_id = courses.id").
+				Order("COALESCE(popularity.enrollment_count, 0) DESC, courses.id DESC")
+		}
+
+		return db.Order("courses.created_at DESC, courses.id DESC")
+	}
 }
 
 func NewCourseRepository(db *gorm.DB) CourseRepository {
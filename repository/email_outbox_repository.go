@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type emailOutboxRepository struct {
+	db *gorm.DB
+}
+
+type EmailOutboxRepository interface {
+	EnqueueEmail(email *model.EmailOutbox) (model.EmailOutbox, error)
+	ClaimPendingEmails(limit int, lease time.Duration) ([]model.EmailOutbox, error)
+	MarkEmailSent(id int) error
+	MarkEmailFailed(id int, sendErr error, final bool) error
+}
+
+func (e *emailOutboxRepository) EnqueueEmail(email *model.EmailOutbox) (model.EmailOutbox, error) {
+	email.Status = model.EmailStatusPending
+
+	err := e.db.Create(email).Error
+	if err != nil {
+		return model.EmailOutbox{}, fmt.Errorf("failed to enqueue email: %w", err)
+	}
+
+	return *email, nil
+}
+
+// ClaimPendingEmails => Ambil email pending dengan SKIP LOCKED lalu tandai sending dalam transaksi singkat,
+// pengiriman dilakukan di luar transaksi. Email sending yang lebih lama dari lease (worker mati di tengah jalan) diambil lagi
+func (e *emailOutboxRepository) ClaimPendingEmails(limit int, lease time.Duration) ([]model.EmailOutbox, error) {
+	var emails []model.EmailOutbox
+	now := time.Now()
+
+	err := e.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND claimed_at < ?)", model.EmailStatusPending, model.EmailStatusSending, now.Add(-lease)).
+			Order("id").
+			Limit(limit).
+			Find(&emails).Error
+		if err != nil {
+			return fmt.Errorf("failed to get pending emails: %w", err)
+		}
+
+		if len(emails) == 0 {
+			return nil
+		}
+
+		ids := make([]int, 0, len(emails))
+		for i := range emails {
+			ids = append(ids, emails[i].ID)
+			emails[i].Status = model.EmailStatusSending
+			emails[i].Attempts++
+			emails[i].ClaimedAt = &now
+		}
+
+		err = tx.
+			Model(&model.EmailOutbox{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":     model.EmailStatusSending,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"claimed_at": now,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to claim emails: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
+
+func (e *emailOutboxRepository) MarkEmailSent(id int) error {
+	err := e.db.
+		Model(&model.EmailOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     model.EmailStatusSent,
+			"sent_at":    time.Now(),
+			"last_error": "",
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	return nil
+}
+
+// MarkEmailFailed => Kembalikan ke pending untuk dicoba lagi, atau failed jika sudah mencapai batas percobaan
+func (e *emailOutboxRepository) MarkEmailFailed(id int, sendErr error, final bool) error {
+	status := model.EmailStatusPending
+	if final {
+		status = model.EmailStatusFailed
+	}
+
+	err := e.db.
+		Model(&model.EmailOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"last_error": sendErr.Error(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	return nil
+}
+
+func NewEmailOutboxRepository(db *gorm.DB) EmailOutboxRepository {
+	return &emailOutboxRepository{db: db}
+}
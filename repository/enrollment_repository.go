@@ -2,6 +2,8 @@ package repository
 
 import (
 	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	"errors"
 	"fmt"
 	"time"
 
@@ -14,35 +16,38 @@ type enrollmentRepository struct {
 
 type EnrollmentRepository interface {
 	IsEnrolled(userID, courseID int) (bool, error)
-	CreateEnrollment(userID, courseID int) error
+	CreateEnrollment(userID, courseID int) (model.Enrollment, error)
 }
 
 func (e *enrollmentRepository) IsEnrolled(userID, courseID int) (bool, error) {
-    var count int64
-    err := e.db.Model(&model.Enrollment{}).
-        Where("student_id = ? AND course_id = ?", userID, courseID).
-        Count(&count).Error
-	
-	if count == 0 {
-		return false, fmt.Errorf("student enrolled yet: %w", err)
+	var count int64
+	err := e.db.Model(&model.Enrollment{}).
+		Where("student_id = ? AND course_id = ?", userID, courseID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check enrollment: %w", err)
 	}
 
-    return true, nil
+	return count > 0, nil
 }
 
-func (e *enrollmentRepository) CreateEnrollment(userID, courseID int) error {
+func (e *enrollmentRepository) CreateEnrollment(userID, courseID int) (model.Enrollment, error) {
 	enrollment := model.Enrollment{
 		StudentID:  userID,
 		CourseID:   courseID,
 		EnrolledAt: time.Now(),
 	}
 
+	// Request paralel bisa lolos cek IsEnrolled, unique index yang menolak
 	err := e.db.Create(&enrollment).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return model.Enrollment{}, apperror.Conflict("user already enrolled in course")
+	}
 	if err != nil {
-		return fmt.Errorf("failed to enroll: %w", err)
+		return model.Enrollment{}, fmt.Errorf("failed to enroll: %w", err)
 	}
 
-	return nil
+	return enrollment, nil
 }
 
 func NewEnrollmentRepository(db *gorm.DB) EnrollmentRepository {
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+type IdempotencyRepository interface {
+	ReserveKey(record *model.IdempotencyKey, ttl time.Duration) (model.IdempotencyKey, bool, error)
+	SaveResponse(id int, statusCode int, body []byte) error
+	DeleteKey(id int) error
+}
+
+// ReserveKey => Simpan key baru, jika key sudah ada kembalikan record lama dengan reserved = false
+func (i *idempotencyRepository) ReserveKey(record *model.IdempotencyKey, ttl time.Duration) (model.IdempotencyKey, bool, error) {
+	// Key yang sudah kedaluwarsa boleh dipakai ulang
+	err := i.db.
+		Where("user_id = ? AND idempotency_key = ? AND created_at < ?", record.UserID, record.Key, time.Now().Add(-ttl)).
+		Delete(&model.IdempotencyKey{}).Error
+	if err != nil {
+		return model.IdempotencyKey{}, false, fmt.Errorf("failed to clean idempotency key: %w", err)
+	}
+
+	result := i.db.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(record)
+	if result.Error != nil {
+		return model.IdempotencyKey{}, false, fmt.Errorf("failed to save idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return *record, true, nil
+	}
+
+	var existing model.IdempotencyKey
+	err = i.db.
+		Where("user_id = ? AND idempotency_key = ?", record.UserID, record.Key).
+		First(&existing).Error
+	if err != nil {
+		return model.IdempotencyKey{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return existing, false, nil
+}
+
+func (i *idempotencyRepository) SaveResponse(id int, statusCode int, body []byte) error {
+	err := i.db.
+		Model(&model.IdempotencyKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"status_code": statusCode, "response_body": body}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+func (i *idempotencyRepository) DeleteKey(id int) error {
+	err := i.db.Delete(&model.IdempotencyKey{}, id).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
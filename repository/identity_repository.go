@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+type IdentityRepository interface {
+	CreateOIDCState(state *model.OIDCState) (model.OIDCState, error)
+	ConsumeOIDCState(stateHash string) (model.OIDCState, error)
+	GetIdentity(provider string, subject string) (model.UserIdentity, error)
+	GetIdentitiesByUserID(userID int, page modelutils.PageQuery) ([]model.UserIdentity, int64, error)
+	CreateIdentity(identity *model.UserIdentity) (model.UserIdentity, error)
+	TouchIdentity(id int, loginAt time.Time) error
+}
+
+// CreateOIDCState => State yang sudah kedaluwarsa ikut dibersihkan
+func (i *identityRepository) CreateOIDCState(state *model.OIDCState) (model.OIDCState, error) {
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("expires_at < ?", time.Now()).Delete(&model.OIDCState{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to clean oidc states: %w", err)
+		}
+
+		err = tx.Create(state).Error
+		if err != nil {
+			return fmt.Errorf("failed to create oidc state: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.OIDCState{}, err
+	}
+
+	return *state, nil
+}
+
+// ConsumeOIDCState => State dihapus saat dibaca supaya callback yang sama tidak bisa diulang
+func (i *identityRepository) ConsumeOIDCState(stateHash string) (model.OIDCState, error) {
+	var states []model.OIDCState
+
+	err := i.db.
+		Clauses(clause.Returning{}).
+		Where("state_hash = ?", stateHash).
+		Delete(&states).Error
+	if err != nil {
+		return model.OIDCState{}, fmt.Errorf("failed to get oidc state: %w", err)
+	}
+
+	if len(states) == 0 {
+		return model.OIDCState{}, apperror.NotFound("oidc state not found")
+	}
+
+	return states[0], nil
+}
+
+func (i *identityRepository) GetIdentity(provider string, subject string) (model.UserIdentity, error) {
+	var identity model.UserIdentity
+
+	err := i.db.
+		Preload("User").
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.UserIdentity{}, apperror.NotFound("identity not found")
+	}
+	if err != nil {
+		return model.UserIdentity{}, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return identity, nil
+}
+
+func (i *identityRepository) GetIdentitiesByUserID(userID int, page modelutils.PageQuery) ([]model.UserIdentity, int64, error) {
+	identities := []model.UserIdentity{}
+	var total int64
+
+	query := i.db.
+		Model(&model.UserIdentity{}).
+		Where("user_id = ?", userID).
+		Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count identities: %w", err)
+	}
+
+	if total == 0 {
+		return identities, 0, nil
+	}
+
+	err = query.
+		Order("created_at ASC").
+		Scopes(paginate(page)).
+		Find(&identities).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get identities: %w", err)
+	}
+
+	return identities, total, nil
+}
+
+func (i *identityRepository) CreateIdentity(identity *model.UserIdentity) (model.UserIdentity, error) {
+	err := i.db.Create(identity).Error
+	if err != nil {
+		return model.UserIdentity{}, fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	return *identity, nil
+}
+
+func (i *identityRepository) TouchIdentity(id int, loginAt time.Time) error {
+	err := i.db.
+		Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		UpdateColumn("last_login_at", loginAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to update identity: %w", err)
+	}
+
+	return nil
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"edu-learn/model"
+	modelutils "edu-learn/utils/model_utils"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type impersonationRepository struct {
+	db *gorm.DB
+}
+
+type ImpersonationRepository interface {
+	CreateAuditLog(log *model.ImpersonationAuditLog) error
+	GetAuditLogs(impersonatorID int, userID int, page modelutils.PageQuery) ([]model.ImpersonationAuditLog, int64, error)
+}
+
+func (i *impersonationRepository) CreateAuditLog(log *model.ImpersonationAuditLog) error {
+	err := i.db.Create(log).Error
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditLogs => Filter 0 berarti tidak difilter
+func (i *impersonationRepository) GetAuditLogs(impersonatorID int, userID int, page modelutils.PageQuery) ([]model.ImpersonationAuditLog, int64, error) {
+	logs := []model.ImpersonationAuditLog{}
+	var total int64
+
+	query := i.db.Model(&model.ImpersonationAuditLog{})
+	if impersonatorID != 0 {
+		query = query.Where("impersonator_id = ?", impersonatorID)
+	}
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	query = query.Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	if total == 0 {
+		return logs, 0, nil
+	}
+
+	err = query.
+		Order("created_at DESC").
+		Scopes(paginate(page)).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+
+	return logs, total, nil
+}
+
+func NewImpersonationRepository(db *gorm.DB) ImpersonationRepository {
+	return &impersonationRepository{db: db}
+}
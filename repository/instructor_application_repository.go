@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type instructorApplicationRepository struct {
+	db *gorm.DB
+}
+
+type InstructorApplicationRepository interface {
+	CreateApplication(application *model.InstructorApplication) (model.InstructorApplication, error)
+	GetApplicationById(id int) (model.InstructorApplication, error)
+	GetApplications(status string, page modelutils.PageQuery) ([]model.InstructorApplication, int64, error)
+	GetApplicationsByUserID(userID int, page modelutils.PageQuery) ([]model.InstructorApplication, int64, error)
+	ReviewApplication(id int, status string, reviewerID int, notes string) (model.InstructorApplication, error)
+}
+
+// CreateApplication => Satu user hanya boleh punya satu pengajuan pending
+func (i *instructorApplicationRepository) CreateApplication(application *model.InstructorApplication) (model.InstructorApplication, error) {
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+
+		err := tx.
+			Model(&model.InstructorApplication{}).
+			Where("user_id = ? AND status = ?", application.UserID, model.ApplicationStatusPending).
+			Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed to check application: %w", err)
+		}
+		if count > 0 {
+			return apperror.Conflict("application already pending")
+		}
+
+		application.Status = model.ApplicationStatusPending
+
+		err = tx.Create(application).Error
+		if err != nil {
+			return fmt.Errorf("failed to create application: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.InstructorApplication{}, err
+	}
+
+	return *application, nil
+}
+
+func (i *instructorApplicationRepository) GetApplicationById(id int) (model.InstructorApplication, error) {
+	var application model.InstructorApplication
+
+	err := i.db.First(&application, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.InstructorApplication{}, apperror.NotFound("application not found")
+	}
+	if err != nil {
+		return model.InstructorApplication{}, fmt.Errorf("failed to get application: %w", err)
+	}
+
+	return application, nil
+}
+
+// GetApplications => Status kosong berarti semua pengajuan
+func (i *instructorApplicationRepository) GetApplications(status string, page modelutils.PageQuery) ([]model.InstructorApplication, int64, error) {
+	applications := []model.InstructorApplication{}
+	var total int64
+
+	query := i.db.Model(&model.InstructorApplication{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	query = query.Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count applications: %w", err)
+	}
+
+	if total == 0 {
+		return applications, 0, nil
+	}
+
+	err = query.
+		Order("created_at").
+		Scopes(paginate(page)).
+		Find(&applications).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get applications: %w", err)
+	}
+
+	return applications, total, nil
+}
+
+func (i *instructorApplicationRepository) GetApplicationsByUserID(userID int, page modelutils.PageQuery) ([]model.InstructorApplication, int64, error) {
+	applications := []model.InstructorApplication{}
+	var total int64
+
+	query := i.db.
+		Model(&model.InstructorApplication{}).
+		Where("user_id = ?", userID).
+		Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count applications: %w", err)
+	}
+
+	if total == 0 {
+		return applications, 0, nil
+	}
+
+	err = query.
+		Order("created_at DESC").
+		Scopes(paginate(page)).
+		Find(&applications).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get applications: %w", err)
+	}
+
+	return applications, total, nil
+}
+
+// ReviewApplication => Update status dan naikkan role user dalam satu transaksi jika disetujui
+func (i *instructorApplicationRepository) ReviewApplication(id int, status string, reviewerID int, notes string) (model.InstructorApplication, error) {
+	var application model.InstructorApplication
+
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&application, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("application not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get application: %w", err)
+		}
+
+		if application.Status != model.ApplicationStatusPending {
+			return apperror.Conflict("application already reviewed")
+		}
+
+		now := time.Now()
+		application.Status = status
+		application.ReviewerID = &reviewerID
+		application.ReviewNotes = notes
+		application.ReviewedAt = &now
+
+		err = tx.
+			Model(&application).
+			Updates(map[string]interface{}{
+				"status":       application.Status,
+				"reviewer_id":  reviewerID,
+				"review_notes": notes,
+				"reviewed_at":  now,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update application: %w", err)
+		}
+
+		if status != model.ApplicationStatusApproved {
+			return nil
+		}
+
+		// User yang sudah bukan student (dihapus, sudah instructor, atau admin) tidak bisa dinaikkan,
+		// transaksi dibatalkan supaya pengajuan tidak tercatat disetujui tanpa perubahan role
+		result := tx.
+			Model(&model.User{}).
+			Where("id = ? AND role = ?", application.UserID, "student").
+			Update("role", "instructor")
+		if result.Error != nil {
+			return fmt.Errorf("failed to promote user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperror.Conflict("applicant is no longer a student")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.InstructorApplication{}, err
+	}
+
+	return application, nil
+}
+
+func NewInstructorApplicationRepository(db *gorm.DB) InstructorApplicationRepository {
+	return &instructorApplicationRepository{db: db}
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"edu-learn/model"
+	modelutils "edu-learn/utils/model_utils"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+type LoginAttemptRepository interface {
+	GetLockedUntil(keys ...string) (time.Time, error)
+	RegisterFailure(key string, threshold int, baseLockout time.Duration, maxLockout time.Duration) (model.LoginAttempt, error)
+	ResetFailures(key string) error
+	CreateLoginHistory(history *model.LoginHistory) (model.LoginHistory, error)
+	GetLoginHistoryByUserID(userID int, page modelutils.PageQuery) ([]model.LoginHistory, int64, error)
+}
+
+// GetLockedUntil => Waktu lock paling lama dari semua key, zero jika tidak ada yang terkunci
+func (l *loginAttemptRepository) GetLockedUntil(keys ...string) (time.Time, error) {
+	var attempts []model.LoginAttempt
+
+	err := l.db.
+		Where("attempt_key IN ? AND locked_until > ?", keys, time.Now()).
+		Find(&attempts).Error
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get login attempts: %w", err)
+	}
+
+	var lockedUntil time.Time
+	for _, attempt := range attempts {
+		if attempt.LockedUntil.After(lockedUntil) {
+			lockedUntil = *attempt.LockedUntil
+		}
+	}
+
+	return lockedUntil, nil
+}
+
+// RegisterFailure => Tambah hitungan gagal, setelah threshold lock berlipat dua tiap kegagalan (maksimal maxLockout)
+func (l *loginAttemptRepository) RegisterFailure(key string, threshold int, baseLockout time.Duration, maxLockout time.Duration) (model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+
+	err := l.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		err := tx.
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoginAttempt{Key: key, LastFailedAt: now}).Error
+		if err != nil {
+			return fmt.Errorf("failed to create login attempt: %w", err)
+		}
+
+		err = tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("attempt_key = ?", key).
+			First(&attempt).Error
+		if err != nil {
+			return fmt.Errorf("failed to get login attempt: %w", err)
+		}
+
+		// Kegagalan lama yang sudah lewat dari maxLockout tidak dihitung lagi
+		if now.Sub(attempt.LastFailedAt) > maxLockout {
+			attempt.FailedCount = 0
+		}
+
+		attempt.FailedCount++
+		attempt.LastFailedAt = now
+		attempt.LockedUntil = nil
+
+		if lockout := lockoutDuration(attempt.FailedCount, threshold, baseLockout, maxLockout); lockout > 0 {
+			lockedUntil := now.Add(lockout)
+			attempt.LockedUntil = &lockedUntil
+		}
+
+		err = tx.
+			Model(&model.LoginAttempt{}).
+			Where("attempt_key = ?", key).
+			Updates(map[string]interface{}{
+				"failed_count":   attempt.FailedCount,
+				"last_failed_at": attempt.LastFailedAt,
+				"locked_until":   attempt.LockedUntil,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update login attempt: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.LoginAttempt{}, err
+	}
+
+	return attempt, nil
+}
+
+// lockoutDuration => Nol sebelum threshold, setelahnya baseLockout berlipat dua tiap kegagalan sampai maxLockout
+func lockoutDuration(failedCount int, threshold int, baseLockout time.Duration, maxLockout time.Duration) time.Duration {
+	if failedCount < threshold {
+		return 0
+	}
+
+	lockout := time.Duration(float64(baseLockout) * math.Pow(2, float64(failedCount-threshold)))
+	if lockout > maxLockout || lockout <= 0 {
+		lockout = maxLockout
+	}
+	return lockout
+}
+
+func (l *loginAttemptRepository) ResetFailures(key string) error {
+	err := l.db.
+		Where("attempt_key = ?", key).
+		Delete(&model.LoginAttempt{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+
+	return nil
+}
+
+func (l *loginAttemptRepository) CreateLoginHistory(history *model.LoginHistory) (model.LoginHistory, error) {
+	err := l.db.Create(history).Error
+	if err != nil {
+		return model.LoginHistory{}, fmt.Errorf("failed to create login history: %w", err)
+	}
+
+	return *history, nil
+}
+
+func (l *loginAttemptRepository) GetLoginHistoryByUserID(userID int, page modelutils.PageQuery) ([]model.LoginHistory, int64, error) {
+	histories := []model.LoginHistory{}
+	var total int64
+
+	query := l.db.
+		Model(&model.LoginHistory{}).
+		Where("user_id = ?", userID).
+		Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count login history: %w", err)
+	}
+
+	if total == 0 {
+		return histories, 0, nil
+	}
+
+	err = query.
+		Order("created_at DESC").
+		Scopes(paginate(page)).
+		Find(&histories).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get login history: %w", err)
+	}
+
+	return histories, total, nil
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		failedCount int
+		want        time.Duration
+	}{
+		{1, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{7, 4 * time.Minute},
+		{10, 32 * time.Minute},
+		{11, time.Hour},
+		{100, time.Hour},
+		{2000, time.Hour},
+	}
+
+	for _, tt := range tests {
+		got := lockoutDuration(tt.failedCount, 5, time.Minute, time.Hour)
+		if got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %v, want %v", tt.failedCount, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
 	"errors"
 	"fmt"
 
@@ -14,7 +16,7 @@ type materialRepository struct {
 
 type MaterialRepository interface {
 	CreateMaterial(material *model.Material) (model.Material, error)
-	GetAllMaterial(idCourse int) ([]model.Material, error)
+	GetAllMaterial(idCourse int, page modelutils.PageQuery) ([]model.Material, int64, error)
 	GetMaterialById(idCourse int, idMaterial int) (model.Material, error)
 	UpdateMaterial(idCourse int, idMaterial int, material *model.Material) (model.Material, error)
 	DeleteMaterial(idCourse int, idMaterial int) error
@@ -36,21 +38,34 @@ func (m *materialRepository) CreateMaterial(material *model.Material) (model.Mat
 	return *material, nil
 }
 
-func (m *materialRepository) GetAllMaterial(idCourse int) ([]model.Material, error) {
-	var materials []model.Material
+func (m *materialRepository) GetAllMaterial(idCourse int, page modelutils.PageQuery) ([]model.Material, int64, error) {
+	materials := []model.Material{}
+	var total int64
 
-	err := m.db.
-		Preload("Course").
-		Find(&materials, "course_id = ?", idCourse).Error
+	query := m.db.
+		Model(&model.Material{}).
+		Where("course_id = ?", idCourse).
+		Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to get materials: %w", err)
+		return nil, 0, fmt.Errorf("failed to count materials: %w", err)
 	}
 
-	if len(materials) == 0 {
-		return nil, fmt.Errorf("no materials found")
+	if total == 0 {
+		return materials, 0, nil
+	}
+
+	err = query.
+		Preload("Course").
+		Order("id").
+		Scopes(paginate(page)).
+		Find(&materials).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get materials: %w", err)
 	}
 
-	return materials, nil
+	return materials, total, nil
 }
 
 func (m *materialRepository) GetMaterialById(idCourse int, idMaterial int) (model.Material, error) {
@@ -61,7 +76,7 @@ func (m *materialRepository) GetMaterialById(idCourse int, idMaterial int) (mode
 		Where("course_id = ?", idCourse).
 		First(&material, idMaterial).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return model.Material{}, fmt.Errorf("material not found")
+		return model.Material{}, apperror.NotFound("material not found")
 	}
 	if err != nil {
 		return model.Material{}, fmt.Errorf("failed to get material: %w", err)
@@ -77,7 +92,7 @@ func (m *materialRepository) UpdateMaterial(idCourse int, idMaterial int, materi
 		Where("course_id = ?", idCourse).
 		First(&existingMaterial, idMaterial).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return model.Material{}, fmt.Errorf("material not found")
+		return model.Material{}, apperror.NotFound("material not found")
 	}
 	if err != nil {
 		return model.Material{}, fmt.Errorf("failed to get material: %w", err)
@@ -100,7 +115,7 @@ func (m *materialRepository) DeleteMaterial(idCourse int, idMaterial int) error
 		Where("course_id = ?", idCourse).
 		First(&material, idMaterial).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return fmt.Errorf("material not found")
+		return apperror.NotFound("material not found")
 	}
 	if err != nil {
 		return fmt.Errorf("failed to get material: %w", err)
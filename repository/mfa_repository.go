@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+type MFARepository interface {
+	GetMFAByUserID(userID int) (model.UserMFA, error)
+	SaveMFASecret(mfa *model.UserMFA) (model.UserMFA, error)
+	ConfirmMFA(userID int, step int64, codeHashes []string) error
+	UseTOTPStep(userID int, step int64) (bool, error)
+	UseRecoveryCode(userID int, codeHash string) (bool, error)
+	ReplaceRecoveryCodes(userID int, codeHashes []string) error
+	DeleteMFA(userID int) error
+	GetRolePolicies(page modelutils.PageQuery) ([]model.MFARolePolicy, int64, error)
+	SetRolePolicy(policy *model.MFARolePolicy) (model.MFARolePolicy, error)
+	IsMFARequired(role string) (bool, error)
+}
+
+func (m *mfaRepository) GetMFAByUserID(userID int) (model.UserMFA, error) {
+	var mfa model.UserMFA
+
+	err := m.db.Where("user_id = ?", userID).First(&mfa).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.UserMFA{}, apperror.NotFound("mfa not found")
+	}
+	if err != nil {
+		return model.UserMFA{}, fmt.Errorf("failed to get mfa: %w", err)
+	}
+
+	return mfa, nil
+}
+
+// SaveMFASecret => Enrollment baru menimpa secret lama yang belum dikonfirmasi
+func (m *mfaRepository) SaveMFASecret(mfa *model.UserMFA) (model.UserMFA, error) {
+	mfa.ConfirmedAt = nil
+	mfa.LastUsedStep = 0
+
+	err := m.db.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "last_used_step", "created_at"}),
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_mfa.confirmed_at IS NULL"}}},
+		}).
+		Create(mfa).Error
+	if err != nil {
+		return model.UserMFA{}, fmt.Errorf("failed to save mfa: %w", err)
+	}
+
+	return *mfa, nil
+}
+
+// ConfirmMFA => Aktifkan MFA dan simpan recovery code dalam satu transaksi
+func (m *mfaRepository) ConfirmMFA(userID int, step int64, codeHashes []string) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Model(&model.UserMFA{}).
+			Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Updates(map[string]interface{}{"confirmed_at": time.Now(), "last_used_step": step})
+		if result.Error != nil {
+			return fmt.Errorf("failed to confirm mfa: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperror.Conflict("mfa already enabled")
+		}
+
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// UseTOTPStep => Kode TOTP hanya sah jika time step-nya lebih baru dari yang terakhir dipakai
+func (m *mfaRepository) UseTOTPStep(userID int, step int64) (bool, error) {
+	result := m.db.
+		Model(&model.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update mfa: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (m *mfaRepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	result := m.db.
+		Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (m *mfaRepository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func (m *mfaRepository) DeleteMFA(userID int) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+
+		err = tx.Where("user_id = ?", userID).Delete(&model.UserMFA{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to delete mfa: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (m *mfaRepository) GetRolePolicies(page modelutils.PageQuery) ([]model.MFARolePolicy, int64, error) {
+	var policies []model.MFARolePolicy
+	var total int64
+
+	query := m.db.Model(&model.MFARolePolicy{}).Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count mfa policies: %w", err)
+	}
+
+	err = query.
+		Order("role").
+		Scopes(paginate(page)).
+		Find(&policies).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get mfa policies: %w", err)
+	}
+
+	return policies, total, nil
+}
+
+func (m *mfaRepository) SetRolePolicy(policy *model.MFARolePolicy) (model.MFARolePolicy, error) {
+	err := m.db.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "role"}},
+			DoUpdates: clause.AssignmentColumns([]string{"required", "updated_at"}),
+		}).
+		Create(policy).Error
+	if err != nil {
+		return model.MFARolePolicy{}, fmt.Errorf("failed to save mfa policy: %w", err)
+	}
+
+	return *policy, nil
+}
+
+func (m *mfaRepository) IsMFARequired(role string) (bool, error) {
+	var count int64
+
+	err := m.db.
+		Model(&model.MFARolePolicy{}).
+		Where("role = ? AND required = ?", role, true).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to get mfa policy: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// replaceRecoveryCodes => Recovery code lama tidak berlaku lagi
+func replaceRecoveryCodes(tx *gorm.DB, userID int, codeHashes []string) error {
+	err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]model.MFARecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, model.MFARecoveryCode{UserID: userID, CodeHash: hash})
+	}
+
+	err = tx.Create(&codes).Error
+	if err != nil {
+		return fmt.Errorf("failed to create recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+func NewMFARepository(db *gorm.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	modelutils "edu-learn/utils/model_utils"
+
+	"gorm.io/gorm"
+)
+
+// paginate => Scope LIMIT/OFFSET untuk satu halaman, dipasang setelah total dihitung
+func paginate(page modelutils.PageQuery) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Offset(page.Offset()).Limit(page.PerPage)
+	}
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+type PasswordResetRepository interface {
+	CreateResetToken(token *model.PasswordResetToken) (model.PasswordResetToken, error)
+	GetResetTokenByHash(hash string) (model.PasswordResetToken, error)
+	ResetPassword(id int, hashedPassword string) (model.PasswordResetToken, error)
+}
+
+// CreateResetToken => Token lama yang belum dipakai otomatis tidak berlaku
+func (p *passwordResetRepository) CreateResetToken(token *model.PasswordResetToken) (model.PasswordResetToken, error) {
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Model(&model.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+		}
+
+		err = tx.Create(token).Error
+		if err != nil {
+			return fmt.Errorf("failed to create reset token: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.PasswordResetToken{}, err
+	}
+
+	return *token, nil
+}
+
+func (p *passwordResetRepository) GetResetTokenByHash(hash string) (model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+
+	err := p.db.Where("token_hash = ?", hash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.PasswordResetToken{}, apperror.NotFound("reset token not found")
+	}
+	if err != nil {
+		return model.PasswordResetToken{}, fmt.Errorf("failed to get reset token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ResetPassword => Tandai token terpakai dan ganti password user dalam satu transaksi
+func (p *passwordResetRepository) ResetPassword(id int, hashedPassword string) (model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&token, id).Error
+		if err != nil {
+			return fmt.Errorf("failed to get reset token: %w", err)
+		}
+
+		if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+			return apperror.BadRequest("invalid or expired reset token")
+		}
+
+		err = tx.
+			Model(&token).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return fmt.Errorf("failed to update reset token: %w", err)
+		}
+
+		err = tx.
+			Model(&model.User{}).
+			Where("id = ?", token.UserID).
+			Update("password", hashedPassword).Error
+		if err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.PasswordResetToken{}, err
+	}
+
+	return token, nil
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
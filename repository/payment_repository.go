@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrDuplicateEvent => Event webhook yang sudah pernah diproses, dibalas 200 oleh controller
+var ErrDuplicateEvent = apperror.Conflict("duplicate event")
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+type PaymentRepository interface {
+	CreatePayment(payment *model.Payment) (model.Payment, error)
+	GetPaymentById(id int) (model.Payment, error)
+	GetPaymentsByUserID(userID int, page modelutils.PageQuery) ([]model.Payment, int64, error)
+	UpdatePayment(id int, payment *model.Payment) (model.Payment, error)
+	HasCompletedPayment(userID, courseID int) (bool, error)
+	HasPendingPayment(userID, courseID int) (bool, error)
+	CompletePayment(id int, payment *model.Payment) (model.Payment, error)
+	GetPaymentByGatewayRef(ref string) (model.Payment, error)
+	ApplyPaymentEvent(id int, eventID string, status string) (model.Payment, error)
+}
+
+func (p *paymentRepository) CreatePayment(payment *model.Payment) (model.Payment, error) {
+	// Request paralel bisa lolos cek HasPendingPayment, partial unique index yang menolak
+	err := p.db.Create(payment).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return model.Payment{}, apperror.Conflict("payment for this course is still pending")
+	}
+	if err != nil {
+		return model.Payment{}, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	return *payment, nil
+}
+
+func (p *paymentRepository) GetPaymentById(id int) (model.Payment, error) {
+	var payment model.Payment
+
+	err := p.db.
+		Preload("Course").
+		First(&payment, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Payment{}, apperror.NotFound("payment not found")
+	}
+	if err != nil {
+		return model.Payment{}, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+func (p *paymentRepository) GetPaymentsByUserID(userID int, page modelutils.PageQuery) ([]model.Payment, int64, error) {
+	payments := []model.Payment{}
+	var total int64
+
+	query := p.db.
+		Model(&model.Payment{}).
+		Where("student_id = ?", userID).
+		Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count payments: %w", err)
+	}
+
+	if total == 0 {
+		return payments, 0, nil
+	}
+
+	err = query.
+		Preload("Course").
+		Order("payment_date DESC").
+		Scopes(paginate(page)).
+		Find(&payments).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get payments: %w", err)
+	}
+
+	return payments, total, nil
+}
+
+func (p *paymentRepository) UpdatePayment(id int, payment *model.Payment) (model.Payment, error) {
+	var existingPayment model.Payment
+
+	err := p.db.First(&existingPayment, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Payment{}, apperror.NotFound("payment not found")
+	}
+	if err != nil {
+		return model.Payment{}, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	err = p.db.
+		Model(&existingPayment).
+		Updates(payment).Error
+	if err != nil {
+		return model.Payment{}, fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	return existingPayment, nil
+}
+
+func (p *paymentRepository) HasCompletedPayment(userID, courseID int) (bool, error) {
+	var count int64
+
+	err := p.db.Model(&model.Payment{}).
+		Where("student_id = ? AND course_id = ? AND status = ?", userID, courseID, model.PaymentStatusCompleted).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check payment: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (p *paymentRepository) HasPendingPayment(userID, courseID int) (bool, error) {
+	var count int64
+
+	err := p.db.Model(&model.Payment{}).
+		Where("student_id = ? AND course_id = ? AND status = ?", userID, courseID, model.PaymentStatusPending).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check payment: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// CompletePayment => Tandai pembayaran selesai dan buat enrollment dalam satu transaksi
+func (p *paymentRepository) CompletePayment(id int, payment *model.Payment) (model.Payment, error) {
+	var existingPayment model.Payment
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&existingPayment, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("payment not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get payment: %w", err)
+		}
+
+		completedAt := time.Now()
+		payment.Status = model.PaymentStatusCompleted
+		payment.CompletedAt = &completedAt
+		err = tx.
+			Model(&existingPayment).
+			Updates(payment).Error
+		if err != nil {
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+
+		return enrollStudent(tx, existingPayment)
+	})
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	return existingPayment, nil
+}
+
+func (p *paymentRepository) GetPaymentByGatewayRef(ref string) (model.Payment, error) {
+	var payment model.Payment
+
+	err := p.db.Where("gateway_ref = ?", ref).First(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Payment{}, apperror.NotFound("payment not found")
+	}
+	if err != nil {
+		return model.Payment{}, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+// ApplyPaymentEvent => Simpan event webhook dan ubah status pembayaran dalam satu transaksi
+func (p *paymentRepository) ApplyPaymentEvent(id int, eventID string, status string) (model.Payment, error) {
+	var existingPayment model.Payment
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		event := model.PaymentEvent{EventID: eventID, PaymentID: id, Status: status}
+		result := tx.
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&event)
+		if result.Error != nil {
+			return fmt.Errorf("failed to save payment event: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrDuplicateEvent
+		}
+
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&existingPayment, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("payment not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get payment: %w", err)
+		}
+
+		// Event dengan status yang sama dianggap pengiriman ulang
+		if existingPayment.Status == status {
+			return nil
+		}
+
+		if !existingPayment.CanTransitionTo(status) {
+			return apperror.Conflict("invalid status transition")
+		}
+
+		// Batas waktu refund dihitung dari completed_at
+		update := map[string]interface{}{"status": status}
+		if status == model.PaymentStatusCompleted {
+			update["completed_at"] = time.Now()
+		}
+
+		err = tx.
+			Model(&existingPayment).
+			Updates(update).Error
+		if err != nil {
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+
+		if status == model.PaymentStatusCompleted {
+			return enrollStudent(tx, existingPayment)
+		}
+
+		if status == model.PaymentStatusRefunded {
+			return revokeEnrollment(tx, existingPayment)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	return existingPayment, nil
+}
+
+// enrollStudent => Buat enrollment untuk pembayaran yang selesai, abaikan jika sudah ada
+func enrollStudent(tx *gorm.DB, payment model.Payment) error {
+	enrollment := model.Enrollment{
+		StudentID:  payment.StudentID,
+		CourseID:   payment.CourseID,
+		EnrolledAt: time.Now(),
+	}
+
+	err := tx.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&enrollment).Error
+	if err != nil {
+		return fmt.Errorf("failed to enroll: %w", err)
+	}
+
+	return nil
+}
+
+// revokeEnrollment => Hapus enrollment milik pembayaran yang sudah direfund penuh
+func revokeEnrollment(tx *gorm.DB, payment model.Payment) error {
+	err := tx.
+		Where("student_id = ? AND course_id = ?", payment.StudentID, payment.CourseID).
+		Delete(&model.Enrollment{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke enrollment: %w", err)
+	}
+
+	return nil
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
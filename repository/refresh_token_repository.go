@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrRefreshTokenReuse => Token sudah pernah dipakai atau dicabut, seluruh family harus dicabut
+var ErrRefreshTokenReuse = apperror.Unauthorized("refresh token reuse detected")
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(token *model.RefreshToken) (model.RefreshToken, error)
+	GetRefreshTokenByHash(hash string) (model.RefreshToken, error)
+	RotateRefreshToken(id int, newToken *model.RefreshToken) (model.RefreshToken, error)
+	RevokeFamily(familyID string) error
+	RevokeUserTokens(userID int) error
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(token *model.RefreshToken) (model.RefreshToken, error) {
+	err := r.db.Create(token).Error
+	if err != nil {
+		return model.RefreshToken{}, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return *token, nil
+}
+
+func (r *refreshTokenRepository) GetRefreshTokenByHash(hash string) (model.RefreshToken, error) {
+	var token model.RefreshToken
+
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.RefreshToken{}, apperror.NotFound("refresh token not found")
+	}
+	if err != nil {
+		return model.RefreshToken{}, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// RotateRefreshToken => Tandai token lama sudah dipakai lalu simpan token baru dalam satu transaksi
+func (r *refreshTokenRepository) RotateRefreshToken(id int, newToken *model.RefreshToken) (model.RefreshToken, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var oldToken model.RefreshToken
+
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&oldToken, id).Error
+		if err != nil {
+			return fmt.Errorf("failed to get refresh token: %w", err)
+		}
+
+		// Request paralel dengan token yang sama dianggap pemakaian ulang
+		if oldToken.UsedAt != nil || oldToken.RevokedAt != nil {
+			return ErrRefreshTokenReuse
+		}
+
+		now := time.Now()
+		err = tx.
+			Model(&oldToken).
+			Update("used_at", now).Error
+		if err != nil {
+			return fmt.Errorf("failed to update refresh token: %w", err)
+		}
+
+		newToken.UserID = oldToken.UserID
+		newToken.FamilyID = oldToken.FamilyID
+		err = tx.Create(newToken).Error
+		if err != nil {
+			return fmt.Errorf("failed to create refresh token: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.RefreshToken{}, err
+	}
+
+	return *newToken, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID string) error {
+	err := r.db.
+		Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepository) RevokeUserTokens(userID int) error {
+	err := r.db.
+		Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
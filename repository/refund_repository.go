@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"errors"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+type RefundRepository interface {
+	ReserveRefund(refund *model.Refund) (model.Refund, error)
+	CompleteRefund(id int) (model.Refund, error)
+	FailRefund(id int) error
+	GetRefundsByPaymentID(paymentID int, page modelutils.PageQuery) ([]model.Refund, int64, error)
+}
+
+// ReserveRefund => Simpan refund pending sebelum gateway dipanggil. Baris pembayaran dikunci
+// supaya refund paralel melihat saldo yang sudah dipotong refund pending lainnya
+func (r *refundRepository) ReserveRefund(refund *model.Refund) (model.Refund, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		payment, err := lockPayment(tx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		if payment.Status != model.PaymentStatusCompleted {
+			return apperror.Validation("payment is not refundable")
+		}
+
+		reserved, err := sumRefunds(tx, payment.ID, model.RefundStatusPending, model.RefundStatusCompleted)
+		if err != nil {
+			return err
+		}
+
+		// Tanpa amount berarti refund penuh sisa pembayaran
+		remaining := toCents(payment.Amount) - toCents(reserved)
+		if refund.Amount == 0 {
+			refund.Amount = float64(remaining) / 100
+		}
+		if refund.Amount <= 0 || toCents(refund.Amount) > remaining {
+			return apperror.Validation("refund amount exceeds remaining balance")
+		}
+
+		refund.Status = model.RefundStatusPending
+		err = tx.Create(refund).Error
+		if err != nil {
+			return fmt.Errorf("failed to create refund: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.Refund{}, err
+	}
+
+	return *refund, nil
+}
+
+// CompleteRefund => Tandai refund berhasil di gateway, refund penuh mengubah status pembayaran dan mencabut enrollment
+func (r *refundRepository) CompleteRefund(id int) (model.Refund, error) {
+	var refund model.Refund
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.First(&refund, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NotFound("refund not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get refund: %w", err)
+		}
+
+		payment, err := lockPayment(tx, refund.PaymentID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.
+			Model(&refund).
+			Update("status", model.RefundStatusCompleted).Error
+		if err != nil {
+			return fmt.Errorf("failed to update refund: %w", err)
+		}
+
+		refunded, err := sumRefunds(tx, payment.ID, model.RefundStatusCompleted)
+		if err != nil {
+			return err
+		}
+
+		if toCents(refunded) < toCents(payment.Amount) {
+			return nil
+		}
+
+		err = tx.
+			Model(&payment).
+			Update("status", model.PaymentStatusRefunded).Error
+		if err != nil {
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+
+		return revokeEnrollment(tx, payment)
+	})
+	if err != nil {
+		return model.Refund{}, err
+	}
+
+	return refund, nil
+}
+
+// FailRefund => Refund ditolak gateway, saldonya dilepas lagi
+func (r *refundRepository) FailRefund(id int) error {
+	err := r.db.
+		Model(&model.Refund{}).
+		Where("id = ? AND status = ?", id, model.RefundStatusPending).
+		Update("status", model.RefundStatusFailed).Error
+	if err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	return nil
+}
+
+func (r *refundRepository) GetRefundsByPaymentID(paymentID int, page modelutils.PageQuery) ([]model.Refund, int64, error) {
+	refunds := []model.Refund{}
+	var total int64
+
+	query := r.db.
+		Model(&model.Refund{}).
+		Where("payment_id = ?", paymentID).
+		Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count refunds: %w", err)
+	}
+
+	if total == 0 {
+		return refunds, 0, nil
+	}
+
+	err = query.
+		Preload("Actor").
+		Order("created_at DESC").
+		Scopes(paginate(page)).
+		Find(&refunds).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get refunds: %w", err)
+	}
+
+	return refunds, total, nil
+}
+
+func lockPayment(tx *gorm.DB, paymentID int) (model.Payment, error) {
+	var payment model.Payment
+
+	err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&payment, paymentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Payment{}, apperror.NotFound("payment not found")
+	}
+	if err != nil {
+		return model.Payment{}, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	return payment, nil
+}
+
+func sumRefunds(db *gorm.DB, paymentID int, statuses ...string) (float64, error) {
+	var total float64
+
+	err := db.Model(&model.Refund{}).
+		Where("payment_id = ? AND status IN ?", paymentID, statuses).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get refunded amount: %w", err)
+	}
+
+	return total, nil
+}
+
+// toCents => Bandingkan nominal dalam sen supaya tidak terpengaruh pembulatan float
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	return &refundRepository{db: db}
+}
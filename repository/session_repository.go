@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+type SessionRepository interface {
+	CreateSession(session *model.Session) (model.Session, error)
+	GetSessionById(id string) (model.Session, error)
+	GetActiveSessionsByUserID(userID int, page modelutils.PageQuery) ([]model.Session, int64, error)
+	TouchSession(id string, ipAddress string, userAgent string, expiresAt time.Time) error
+	RevokeSession(id string) error
+	RevokeUserSessions(userID int) error
+	IsSessionRevoked(id string) (bool, error)
+}
+
+func (s *sessionRepository) CreateSession(session *model.Session) (model.Session, error) {
+	err := s.db.Create(session).Error
+	if err != nil {
+		return model.Session{}, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return *session, nil
+}
+
+func (s *sessionRepository) GetSessionById(id string) (model.Session, error) {
+	var session model.Session
+
+	err := s.db.Where("id = ?", id).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Session{}, apperror.NotFound("session not found")
+	}
+	if err != nil {
+		return model.Session{}, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
+
+func (s *sessionRepository) GetActiveSessionsByUserID(userID int, page modelutils.PageQuery) ([]model.Session, int64, error) {
+	sessions := []model.Session{}
+	var total int64
+
+	query := s.db.
+		Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count sessions: %w", err)
+	}
+
+	if total == 0 {
+		return sessions, 0, nil
+	}
+
+	err = query.
+		Order("last_seen_at DESC").
+		Scopes(paginate(page)).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	return sessions, total, nil
+}
+
+// TouchSession => Dipanggil setiap refresh, IP dan user agent ikut diperbarui
+func (s *sessionRepository) TouchSession(id string, ipAddress string, userAgent string, expiresAt time.Time) error {
+	err := s.db.
+		Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"ip_address":   ipAddress,
+			"user_agent":   userAgent,
+			"last_seen_at": time.Now(),
+			"expires_at":   expiresAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeSession => Sesi dan seluruh refresh token dalam family-nya dicabut bersamaan
+func (s *sessionRepository) RevokeSession(id string) error {
+	now := time.Now()
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Model(&model.Session{}).
+			Where("id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+
+		err = tx.
+			Model(&model.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *sessionRepository) RevokeUserSessions(userID int) error {
+	err := s.db.
+		Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// IsSessionRevoked => Sesi yang sudah tidak ada (user dihapus) dianggap dicabut
+func (s *sessionRepository) IsSessionRevoked(id string) (bool, error) {
+	var session model.Session
+
+	err := s.db.Select("id", "revoked_at").Where("id = ?", id).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return true, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session.RevokedAt != nil, nil
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
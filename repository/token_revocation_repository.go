@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"edu-learn/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tokenRevocationRepository struct {
+	db *gorm.DB
+}
+
+type TokenRevocationRepository interface {
+	RevokeToken(token *model.RevokedToken) error
+	IsTokenRevoked(jti string) (bool, error)
+	RevokeUserTokens(userID int, revokedAt time.Time) error
+	GetUserRevokedAt(userID int) (time.Time, error)
+	DeleteExpiredTokens() error
+}
+
+func (t *tokenRevocationRepository) RevokeToken(token *model.RevokedToken) error {
+	err := t.db.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(token).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	return nil
+}
+
+func (t *tokenRevocationRepository) IsTokenRevoked(jti string) (bool, error) {
+	var count int64
+
+	err := t.db.Model(&model.RevokedToken{}).
+		Where("jti = ?", jti).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check revoked token: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (t *tokenRevocationRepository) RevokeUserTokens(userID int, revokedAt time.Time) error {
+	err := t.db.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"revoked_at"}),
+		}).
+		Create(&model.UserTokenRevocation{UserID: userID, RevokedAt: revokedAt}).Error
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserRevokedAt => Kembalikan zero time jika token user belum pernah dicabut
+func (t *tokenRevocationRepository) GetUserRevokedAt(userID int) (time.Time, error) {
+	var revocation model.UserTokenRevocation
+
+	err := t.db.First(&revocation, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get user token revocation: %w", err)
+	}
+
+	return revocation.RevokedAt, nil
+}
+
+func (t *tokenRevocationRepository) DeleteExpiredTokens() error {
+	err := t.db.
+		Where("expires_at < ?", time.Now()).
+		Delete(&model.RevokedToken{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete expired tokens: %w", err)
+	}
+
+	return nil
+}
+
+func NewTokenRevocationRepository(db *gorm.DB) TokenRevocationRepository {
+	return &tokenRevocationRepository{db: db}
+}
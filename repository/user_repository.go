@@ -2,8 +2,11 @@ package repository
 
 import (
 	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -15,10 +18,13 @@ type userRepository struct {
 type UserRepository interface {
 	GetUserByEmail(email string) (model.User, error)
 	CreateUser(user *model.User) (model.User, error)
-	GetAllUsers() ([]model.User, error)
+	GetAllUsers(page modelutils.PageQuery) ([]model.User, int64, error)
+	GetUsersByAccountType(accountType string, page modelutils.PageQuery) ([]model.User, int64, error)
 	GetUserById(id int) (model.User, error)
 	UpdateUser(id int, user *model.User) (model.User, error)
 	DeleteUser(id int) error
+	MarkEmailVerified(id int, verifiedAt time.Time) error
+	UpdatePassword(id int, hashedPassword string) error
 }
 
 func (u *userRepository) GetUserByEmail(email string) (model.User, error) {
@@ -27,7 +33,7 @@ func (u *userRepository) GetUserByEmail(email string) (model.User, error) {
 	// Dapat diubah jika user login dengan email
 	err := u.db.Where("email = ?", email).First(&user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return model.User{}, fmt.Errorf("user not found")
+		return model.User{}, apperror.NotFound("user not found")
 	}
 	if err != nil {
 		return model.User{}, err
@@ -39,6 +45,9 @@ func (u *userRepository) GetUserByEmail(email string) (model.User, error) {
 // Method untuk user repository
 func (u *userRepository) CreateUser(user *model.User) (model.User, error) {
 	err := u.db.Create(user).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return model.User{}, apperror.Conflict("email already registered")
+	}
 	if err != nil {
 		return model.User{}, fmt.Errorf("failed to create user: %w", err)
 	}
@@ -46,35 +55,67 @@ func (u *userRepository) CreateUser(user *model.User) (model.User, error) {
 	return *user, nil
 }
 
-func (u *userRepository) GetAllUsers() ([]model.User, error) {
-	var users []model.User
+func (u *userRepository) GetAllUsers(page modelutils.PageQuery) ([]model.User, int64, error) {
+	users := []model.User{}
+	var total int64
 
-	err := u.db.
-		Preload("Courses").
-		Preload("Enrollments").
-		Preload("Payments").
+	query := u.db.Model(&model.User{}).Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	if total == 0 {
+		return users, 0, nil
+	}
+
+	err = query.
+		Order("id").
+		Scopes(paginate(page)).
 		Find(&users).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, 0, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+func (u *userRepository) GetUsersByAccountType(accountType string, page modelutils.PageQuery) ([]model.User, int64, error) {
+	users := []model.User{}
+	var total int64
+
+	query := u.db.
+		Model(&model.User{}).
+		Where("account_type = ?", accountType).
+		Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	if total == 0 {
+		return users, 0, nil
 	}
 
-	if len(users) == 0 {
-		return nil, fmt.Errorf("no users found")
+	err = query.
+		Order("id").
+		Scopes(paginate(page)).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get users: %w", err)
 	}
 
-	return users, nil
+	return users, total, nil
 }
 
 func (u *userRepository) GetUserById(id int) (model.User, error) {
 	var user model.User
 
-	err := u.db.
-		Preload("Courses").
-		Preload("Enrollments").
-		Preload("Payments").
-		First(&user, id).Error
+	err := u.db.First(&user, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return model.User{}, fmt.Errorf("user not found")
+		return model.User{}, apperror.NotFound("user not found")
 	}
 	if err != nil {
 		return model.User{}, fmt.Errorf("failed to get user: %w", err)
@@ -89,16 +130,36 @@ func (u *userRepository) UpdateUser(id int, user *model.User) (model.User, error
 	// Cari user berdasarkan ID
 	err := u.db.First(&existingUser, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return model.User{}, fmt.Errorf("user not found")
+		return model.User{}, apperror.NotFound("user not found")
 	}
 	if err != nil {
 		return model.User{}, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// Update user
-	err = u.db.
-		Model(&existingUser).
-		Updates(user).Error
+	// Email baru belum terbukti milik user, status verifikasi direset. Updates dengan struct melewati
+	// field nil, jadi email_verified_at dikosongkan dengan update terpisah di transaksi yang sama
+	emailChanged := user.Email != "" && user.Email != existingUser.Email
+
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Model(&existingUser).
+			Updates(user).Error
+		if err != nil {
+			return err
+		}
+
+		if !emailChanged {
+			return nil
+		}
+
+		existingUser.EmailVerifiedAt = nil
+		return tx.
+			Model(&existingUser).
+			Update("email_verified_at", nil).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return model.User{}, apperror.Conflict("email already registered")
+	}
 	if err != nil {
 		return model.User{}, fmt.Errorf("failed to update user: %w", err)
 	}
@@ -112,7 +173,7 @@ func (u *userRepository) DeleteUser(id int) error {
 	// Cari user berdasarkan ID
 	err := u.db.First(&user, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return fmt.Errorf("user not found")
+		return apperror.NotFound("user not found")
 	}
 	if err != nil {
 		return fmt.Errorf("failed to find user: %w", err)
@@ -127,6 +188,32 @@ func (u *userRepository) DeleteUser(id int) error {
 	return nil
 }
 
+// MarkEmailVerified => Hanya mengisi email_verified_at jika belum pernah diverifikasi
+func (u *userRepository) MarkEmailVerified(id int, verifiedAt time.Time) error {
+	err := u.db.
+		Model(&model.User{}).
+		Where("id = ? AND email_verified_at IS NULL", id).
+		UpdateColumn("email_verified_at", verifiedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePassword => Ganti hash password saja, dipakai saat rehash dengan cost baru
+func (u *userRepository) UpdatePassword(id int, hashedPassword string) error {
+	err := u.db.
+		Model(&model.User{}).
+		Where("id = ?", id).
+		UpdateColumn("password", hashedPassword).Error
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	return nil
+}
+
 func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
@@ -7,9 +7,14 @@ import (
 	"edu-learn/repository"
 	"edu-learn/usecase"
 	"edu-learn/utils/service"
+	"edu-learn/utils/validation"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	_ "github.com/lib/pq"
 
 	"gorm.io/driver/postgres"
@@ -18,43 +23,108 @@ import (
 
 type Server struct {
 	// Instean usecase
-	enrollmentUC usecase.EnrollmentUseCase
-	materialUC   usecase.MaterialUseCase
-	courseUC     usecase.CourseUseCase
-	userUC       usecase.UserUseCase
-	authUC       usecase.AuthenticationUseCase
-	jwtService   service.JwtService
-	engine       *gin.Engine
-	host         string
+	enrollmentUC      usecase.EnrollmentUseCase
+	paymentUC         usecase.PaymentUseCase
+	materialUC        usecase.MaterialUseCase
+	courseUC          usecase.CourseUseCase
+	userUC            usecase.UserUseCase
+	authUC            usecase.AuthenticationUseCase
+	passwordResetUC   usecase.PasswordResetUseCase
+	emailVerifyUC     usecase.EmailVerificationUseCase
+	mfaUC             usecase.MFAUseCase
+	applicationUC     usecase.InstructorApplicationUseCase
+	apiKeyUC          usecase.APIKeyUseCase
+	impersonationUC   usecase.ImpersonationUseCase
+	sessionUC         usecase.SessionUseCase
+	mailUC            usecase.MailUseCase
+	jwtService        service.JwtService
+	revocationService service.TokenRevocationService
+	apiKeyService     service.APIKeyService
+	oidcProvider      service.OIDCProvider
+	engine            *gin.Engine
+	host              string
+
+	idempotencyRepo   repository.IdempotencyRepository
+	impersonationRepo repository.ImpersonationRepository
 }
 
 func NewServer() *Server {
-	cfg, _ := config.NewConfig()
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
 
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=Asia/Jakarta", cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.Database)
 
-	// Setting menggunakan gorm
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// Setting menggunakan gorm, TranslateError supaya pelanggaran unique bisa dicek dengan gorm.ErrDuplicatedKey
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		panic(err)
 	}
 
 	jwtService := service.NewJwtService(cfg.TokenConfig)
 
+	paymentGateway, err := service.NewPaymentGateway(cfg.PaymentConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	mailSender, err := service.NewMailSender(cfg.MailConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	oidcProvider, err := service.NewOIDCLoginProvider(*cfg)
+	if err != nil {
+		panic(err)
+	}
+
 	// Instean repository
 	userRepo := repository.NewUserRepository(db)
 	courseRepo := repository.NewCourseRepository(db)
 	materialRepo := repository.NewMaterialRepository(db)
 	enrollemtRepo := repository.NewEnrollmentRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	tokenRevocationRepo := repository.NewTokenRevocationRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	emailOutboxRepo := repository.NewEmailOutboxRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	applicationRepo := repository.NewInstructorApplicationRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	impersonationRepo := repository.NewImpersonationRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+
+	passwordService, err := service.NewPasswordService(cfg.PasswordConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	revocationService := service.NewTokenRevocationService(tokenRevocationRepo, refreshTokenRepo, sessionRepo, cfg.TokenConfig)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
 
 	// Instean usecase
-	userUseCase := usecase.NewUserUseCase(userRepo)
+	mailUseCase := usecase.NewMailUseCase(emailOutboxRepo, mailSender, cfg.MailFrom)
+	emailVerificationUseCase := usecase.NewEmailVerificationUseCase(userRepo, mailUseCase, *cfg)
+	userUseCase := usecase.NewUserUseCase(userRepo, revocationService, passwordService, emailVerificationUseCase)
 	courseUseCase := usecase.NewCourseUsecase(courseRepo, userRepo)
-	materialUseCase := usecase.NewMaterialUseCase(materialRepo, courseRepo)
-	enrollmentUseCase := usecase.NewEnrollmentUseCase(enrollemtRepo)
+	materialUseCase := usecase.NewMaterialUseCase(materialRepo, courseRepo, enrollemtRepo)
+	enrollmentUseCase := usecase.NewEnrollmentUseCase(enrollemtRepo, courseRepo, paymentRepo)
+	paymentUseCase := usecase.NewPaymentUseCase(paymentRepo, refundRepo, courseRepo, enrollemtRepo, paymentGateway, cfg.PaymentConfig)
+
+	passwordResetUseCase := usecase.NewPasswordResetUseCase(passwordResetRepo, userRepo, mailUseCase, revocationService, passwordService, *cfg)
+	mfaUseCase := usecase.NewMFAUseCase(mfaRepo, userRepo, loginAttemptRepo, *cfg)
+	applicationUseCase := usecase.NewInstructorApplicationUseCase(applicationRepo, userRepo, mailUseCase, revocationService)
+	apiKeyUseCase := usecase.NewAPIKeyUseCase(apiKeyRepo, userRepo, apiKeyService, userUseCase)
+	impersonationUseCase := usecase.NewImpersonationUseCase(impersonationRepo, userRepo, jwtService)
+	sessionUseCase := usecase.NewSessionUseCase(sessionRepo, userRepo, revocationService)
 
 	// Auth usecase
-	authUseCase := usecase.NewAuthenticationUsecase(userUseCase, jwtService)
+	authUseCase := usecase.NewAuthenticationUsecase(userUseCase, jwtService, revocationService, refreshTokenRepo, loginAttemptRepo, sessionRepo, emailVerificationUseCase, mfaUseCase, identityRepo, oidcProvider, *cfg)
 
 	engine := gin.Default()
 
@@ -62,32 +132,101 @@ func NewServer() *Server {
 
 	return &Server{
 		// Instean usecase
-		enrollmentUC: enrollmentUseCase,
-		materialUC:   materialUseCase,
-		courseUC:     courseUseCase,
-		userUC:       userUseCase,
-		authUC:       authUseCase,
-		jwtService:   jwtService,
-		engine:       engine,
-		host:         host,
+		enrollmentUC:      enrollmentUseCase,
+		paymentUC:         paymentUseCase,
+		materialUC:        materialUseCase,
+		courseUC:          courseUseCase,
+		userUC:            userUseCase,
+		authUC:            authUseCase,
+		passwordResetUC:   passwordResetUseCase,
+		emailVerifyUC:     emailVerificationUseCase,
+		mfaUC:             mfaUseCase,
+		applicationUC:     applicationUseCase,
+		apiKeyUC:          apiKeyUseCase,
+		impersonationUC:   impersonationUseCase,
+		sessionUC:         sessionUseCase,
+		mailUC:            mailUseCase,
+		jwtService:        jwtService,
+		revocationService: revocationService,
+		apiKeyService:     apiKeyService,
+		oidcProvider:      oidcProvider,
+		engine:            engine,
+		host:              host,
+
+		idempotencyRepo:   idempotencyRepo,
+		impersonationRepo: impersonationRepo,
 	}
 }
 
 func (s *Server) initRoute() {
+	// Field yang tidak ada di DTO (id, role, created_at, ...) ditolak, bukan diabaikan diam-diam
+	binding.EnableDecoderDisallowUnknownFields = true
+
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if ok {
+		err := validation.Register(validate)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// Error dari c.Error di controller diubah menjadi status dan body yang seragam
+	s.engine.Use(middleware.ErrorHandler())
+
 	rg := s.engine.Group("/api")
 
-	authMiddleware := middleware.NewAuthMiddleware(s.jwtService)
+	controller.NewJwksController(s.jwtService, s.engine.Group("")).Route()
+
+	authMiddleware := middleware.NewAuthMiddleware(s.jwtService, s.revocationService, s.apiKeyService, s.impersonationRepo)
+	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(s.idempotencyRepo)
 
 	// Instean controller
-	controller.NewAuthController(s.authUC, rg).Route()
+	controller.NewAuthController(s.authUC, rg, authMiddleware).Route()
+	controller.NewOIDCController(s.authUC, s.oidcProvider, rg, authMiddleware).Route()
+	controller.NewPasswordController(s.passwordResetUC, rg).Route()
+	controller.NewEmailVerificationController(s.emailVerifyUC, rg).Route()
+	controller.NewMFAController(s.mfaUC, rg, authMiddleware).Route()
+	controller.NewInstructorApplicationController(s.applicationUC, rg, authMiddleware).Route()
+	controller.NewAPIKeyController(s.apiKeyUC, rg, authMiddleware).Route()
+	controller.NewImpersonationController(s.impersonationUC, rg, authMiddleware).Route()
+	controller.NewSessionController(s.sessionUC, rg, authMiddleware).Route()
 	controller.NewUserController(s.userUC, rg, authMiddleware).Route()
-	controller.NewCourseController(s.courseUC, rg, authMiddleware).Route()
+	controller.NewCourseController(s.courseUC, rg, authMiddleware, idempotencyMiddleware).Route()
 	controller.NewMaterialController(s.materialUC, rg, authMiddleware).Route()
-	controller.NewEnrollmentController(s.enrollmentUC, rg, authMiddleware).Route()
+	controller.NewEnrollmentController(s.enrollmentUC, rg, authMiddleware, idempotencyMiddleware).Route()
+	controller.NewPaymentController(s.paymentUC, rg, authMiddleware, idempotencyMiddleware).Route()
+}
+
+// runMailWorker => Kirim email dari outbox secara berkala
+func (s *Server) runMailWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_, err := s.mailUC.DispatchPendingEmails()
+		if err != nil {
+			log.Printf("failed to dispatch emails: %v", err)
+		}
+	}
+}
+
+// runCleanupWorker => Hapus data yang sudah kedaluwarsa secara berkala
+func (s *Server) runCleanupWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := s.revocationService.DeleteExpiredTokens()
+		if err != nil {
+			log.Printf("failed to delete expired revoked tokens: %v", err)
+		}
+	}
 }
 
 func (s *Server) Run() {
 	s.initRoute()
+	go s.runMailWorker(10 * time.Second)
+	go s.runCleanupWorker(time.Hour)
 	s.engine.SetTrustedProxies([]string{"127.0.0.1"})
 
 	err := s.engine.Run(s.host)
@@ -1,8 +1,8 @@
 -- Insert dummy users
-INSERT INTO users (name, email, password, role) VALUES
-    ('Alice Johnson', 'alice@example.com', '$2y$10$X31G/./0HmxpxOcUHEsYfOuNvDyhBYY7US6nxwFZpa1A1AZzGn4a.', 'student'),
-    ('Bob Smith', 'bob@example.com', '$2y$10$X31G/./0HmxpxOcUHEsYfOuNvDyhBYY7US6nxwFZpa1A1AZzGn4a.', 'instructor'),
-    ('Charlie Brown', 'charlie@example.com', '$2y$10$X31G/./0HmxpxOcUHEsYfOuNvDyhBYY7US6nxwFZpa1A1AZzGn4a.', 'admin');
+INSERT INTO users (name, email, password, role, email_verified_at) VALUES
+    ('Alice Johnson', 'alice@example.com', '$2y$10$X31G/./0HmxpxOcUHEsYfOuNvDyhBYY7US6nxwFZpa1A1AZzGn4a.', 'student', CURRENT_TIMESTAMP),
+    ('Bob Smith', 'bob@example.com', '$2y$10$X31G/./0HmxpxOcUHEsYfOuNvDyhBYY7US6nxwFZpa1A1AZzGn4a.', 'instructor', CURRENT_TIMESTAMP),
+    ('Charlie Brown', 'charlie@example.com', '$2y$10$X31G/./0HmxpxOcUHEsYfOuNvDyhBYY7US6nxwFZpa1A1AZzGn4a.', 'admin', CURRENT_TIMESTAMP);
 
 -- Insert dummy courses
 INSERT INTO courses (title, description, instructor_id, price, category) VALUES
@@ -0,0 +1,55 @@
+-- Full-text search kursus (GET /courses/search) untuk database yang dibuat dari schema.sql lama.
+-- Aman dijalankan ulang.
+BEGIN;
+
+-- Toleransi typo pada judul memakai operator trigram <%
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- Bobot: judul A, kategori B, judul materi C, deskripsi D.
+-- Konfigurasi 'simple' karena isi kursus campuran Bahasa Indonesia dan Inggris
+CREATE OR REPLACE FUNCTION course_search_vector(course courses) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(course.title, '')), 'A')
+        || setweight(to_tsvector('simple', coalesce(course.category, '')), 'B')
+        || setweight(to_tsvector('simple', coalesce((SELECT string_agg(title, ' ') FROM materials WHERE course_id = course.id), '')), 'C')
+        || setweight(to_tsvector('simple', coalesce(course.description, '')), 'D');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION courses_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := course_search_vector(NEW);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- Judul materi ikut dicari, jadi perubahan materi menghitung ulang vector kursusnya
+CREATE OR REPLACE FUNCTION materials_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE courses SET search_vector = course_search_vector(courses) WHERE id = OLD.course_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE courses SET search_vector = course_search_vector(courses) WHERE id = NEW.course_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS courses_search_vector_update ON courses;
+CREATE TRIGGER courses_search_vector_update
+    BEFORE INSERT OR UPDATE OF title, description, category ON courses
+    FOR EACH ROW EXECUTE FUNCTION courses_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS materials_search_vector_update ON materials;
+CREATE TRIGGER materials_search_vector_update
+    AFTER INSERT OR UPDATE OF title, course_id OR DELETE ON materials
+    FOR EACH ROW EXECUTE FUNCTION materials_search_vector_trigger();
+
+-- Isi vector untuk kursus yang sudah ada
+UPDATE courses SET search_vector = course_search_vector(courses);
+
+CREATE INDEX IF NOT EXISTS idx_courses_search_vector ON courses USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_courses_title_trgm ON courses USING GIN (title gin_trgm_ops);
+
+COMMIT;
@@ -0,0 +1,13 @@
+-- Satu pembayaran pending per siswa dan kursus.
+-- Pending ganda yang sudah ada ditandai failed lebih dulu, kecuali yang paling baru.
+BEGIN;
+
+UPDATE payments SET status = 'failed'
+WHERE status = 'pending'
+  AND id NOT IN (
+      SELECT MAX(id) FROM payments WHERE status = 'pending' GROUP BY student_id, course_id
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_pending ON payments(student_id, course_id) WHERE status = 'pending';
+
+COMMIT;
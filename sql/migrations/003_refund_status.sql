@@ -0,0 +1,13 @@
+-- Refund dicatat pending sebelum gateway dipanggil, dan riwayat refund tetap ada saat akun actor dihapus.
+BEGIN;
+
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'completed'
+    CHECK (status IN ('pending', 'completed', 'failed'));
+ALTER TABLE refunds ALTER COLUMN status SET DEFAULT 'pending';
+
+ALTER TABLE refunds ALTER COLUMN actor_id DROP NOT NULL;
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_actor_id_fkey;
+ALTER TABLE refunds ADD CONSTRAINT refunds_actor_id_fkey
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL;
+
+COMMIT;
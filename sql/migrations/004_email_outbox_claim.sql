@@ -0,0 +1,13 @@
+-- Worker outbox menandai email sending sebelum mengirim di luar transaksi.
+BEGIN;
+
+ALTER TABLE email_outbox ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMP;
+
+ALTER TABLE email_outbox DROP CONSTRAINT IF EXISTS email_outbox_status_check;
+ALTER TABLE email_outbox ADD CONSTRAINT email_outbox_status_check
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed'));
+
+DROP INDEX IF EXISTS idx_email_outbox_pending;
+CREATE INDEX idx_email_outbox_pending ON email_outbox(id) WHERE status IN ('pending', 'sending');
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Batas waktu refund dihitung sejak pembayaran selesai, bukan sejak checkout dibuat.
+BEGIN;
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+
+-- Waktu selesai pembayaran lama tidak tercatat, payment_date dipakai sebagai perkiraan
+UPDATE payments SET completed_at = payment_date
+WHERE status IN ('completed', 'refunded') AND completed_at IS NULL;
+
+COMMIT;
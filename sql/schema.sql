@@ -1,10 +1,31 @@
 -- Active: 1732459310921@@127.0.0.1@5432@edulearn_db
 -- Schema edulearn_db
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 DROP TABLE IF EXISTS users CASCADE;
 DROP TABLE IF EXISTS courses CASCADE;
 DROP TABLE IF EXISTS enrollments CASCADE;
 DROP TABLE IF EXISTS materials CASCADE;
 DROP TABLE IF EXISTS payments CASCADE;
+DROP TABLE IF EXISTS payment_events CASCADE;
+DROP TABLE IF EXISTS refunds CASCADE;
+DROP TABLE IF EXISTS idempotency_keys CASCADE;
+DROP TABLE IF EXISTS refresh_tokens CASCADE;
+DROP TABLE IF EXISTS revoked_tokens CASCADE;
+DROP TABLE IF EXISTS user_token_revocations CASCADE;
+DROP TABLE IF EXISTS password_reset_tokens CASCADE;
+DROP TABLE IF EXISTS email_outbox CASCADE;
+DROP TABLE IF EXISTS login_attempts CASCADE;
+DROP TABLE IF EXISTS login_history CASCADE;
+DROP TABLE IF EXISTS user_mfa CASCADE;
+DROP TABLE IF EXISTS mfa_recovery_codes CASCADE;
+DROP TABLE IF EXISTS mfa_role_policies CASCADE;
+DROP TABLE IF EXISTS instructor_applications CASCADE;
+DROP TABLE IF EXISTS api_keys CASCADE;
+DROP TABLE IF EXISTS user_identities CASCADE;
+DROP TABLE IF EXISTS oidc_states CASCADE;
+DROP TABLE IF EXISTS impersonation_audit_logs CASCADE;
+DROP TABLE IF EXISTS sessions CASCADE;
 
 CREATE TABLE users (
     id SERIAL PRIMARY KEY,
@@ -13,7 +34,9 @@ CREATE TABLE users (
     password VARCHAR(255) NOT NULL,
     role VARCHAR(50) CHECK (role IN ('student', 'instructor', 'admin')) NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    email_verified_at TIMESTAMP,
+    account_type VARCHAR(20) CHECK (account_type IN ('user', 'service')) NOT NULL DEFAULT 'user'
 );
 
 CREATE TABLE courses (
@@ -24,16 +47,27 @@ CREATE TABLE courses (
     price DECIMAL(10,2) DEFAULT 0,
     category VARCHAR(100),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    search_vector tsvector
 );
 
+CREATE INDEX idx_courses_category ON courses(category, created_at);
+CREATE INDEX idx_courses_instructor ON courses(instructor_id, created_at);
+CREATE INDEX idx_courses_created_at ON courses(created_at);
+CREATE INDEX idx_courses_price ON courses(price);
+CREATE INDEX idx_courses_search_vector ON courses USING GIN (search_vector);
+CREATE INDEX idx_courses_title_trgm ON courses USING GIN (title gin_trgm_ops);
+
 CREATE TABLE enrollments (
     id SERIAL PRIMARY KEY,
     student_id INT REFERENCES users(id) ON DELETE CASCADE,
     course_id INT REFERENCES courses(id) ON DELETE CASCADE,
-    enrolled_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    enrolled_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (student_id, course_id)
 );
 
+CREATE INDEX idx_enrollments_course ON enrollments(course_id);
+
 CREATE TABLE materials (
     id SERIAL PRIMARY KEY,
     course_id INT REFERENCES courses(id) ON DELETE CASCADE,
@@ -43,11 +77,262 @@ CREATE TABLE materials (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Search vector kursus: judul A, kategori B, judul materi C, deskripsi D (lihat sql/migrations/001_course_search.sql)
+CREATE OR REPLACE FUNCTION course_search_vector(course courses) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(course.title, '')), 'A')
+        || setweight(to_tsvector('simple', coalesce(course.category, '')), 'B')
+        || setweight(to_tsvector('simple', coalesce((SELECT string_agg(title, ' ') FROM materials WHERE course_id = course.id), '')), 'C')
+        || setweight(to_tsvector('simple', coalesce(course.description, '')), 'D');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION courses_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := course_search_vector(NEW);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION materials_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE courses SET search_vector = course_search_vector(courses) WHERE id = OLD.course_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE courses SET search_vector = course_search_vector(courses) WHERE id = NEW.course_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER courses_search_vector_update
+    BEFORE INSERT OR UPDATE OF title, description, category ON courses
+    FOR EACH ROW EXECUTE FUNCTION courses_search_vector_trigger();
+
+CREATE TRIGGER materials_search_vector_update
+    AFTER INSERT OR UPDATE OF title, course_id OR DELETE ON materials
+    FOR EACH ROW EXECUTE FUNCTION materials_search_vector_trigger();
+
 CREATE TABLE payments (
     id SERIAL PRIMARY KEY,
     student_id INT REFERENCES users(id) ON DELETE CASCADE,
     course_id INT REFERENCES courses(id) ON DELETE CASCADE,
     amount DECIMAL(10,2) NOT NULL,
-    status VARCHAR(50) CHECK (status IN ('pending', 'completed', 'failed')) NOT NULL,
-    payment_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    status VARCHAR(50) CHECK (status IN ('pending', 'completed', 'failed', 'refunded')) NOT NULL,
+    gateway VARCHAR(50),
+    gateway_ref VARCHAR(255),
+    payment_url VARCHAR(255),
+    payment_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+-- Satu pembayaran pending per siswa dan kursus
+CREATE UNIQUE INDEX idx_payments_pending ON payments(student_id, course_id) WHERE status = 'pending';
+
+CREATE TABLE payment_events (
+    id SERIAL PRIMARY KEY,
+    event_id VARCHAR(255) UNIQUE NOT NULL,
+    payment_id INT REFERENCES payments(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE refunds (
+    id SERIAL PRIMARY KEY,
+    payment_id INT REFERENCES payments(id) ON DELETE CASCADE,
+    amount DECIMAL(10,2) NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) CHECK (status IN ('pending', 'completed', 'failed')) NOT NULL DEFAULT 'pending',
+    actor_id INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE idempotency_keys (
+    id SERIAL PRIMARY KEY,
+    idempotency_key VARCHAR(255) NOT NULL,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INT DEFAULT 0,
+    response_body BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, idempotency_key)
+);
+
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    family_id VARCHAR(64) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- Tanpa foreign key supaya pencabutan tetap berlaku setelah user dihapus
+CREATE TABLE user_token_revocations (
+    user_id INT PRIMARY KEY,
+    revoked_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE password_reset_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE email_outbox (
+    id SERIAL PRIMARY KEY,
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) CHECK (status IN ('pending', 'sending', 'sent', 'failed')) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    claimed_at TIMESTAMP,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX idx_email_outbox_pending ON email_outbox(id) WHERE status IN ('pending', 'sending');
+
+CREATE TABLE login_attempts (
+    attempt_key VARCHAR(320) PRIMARY KEY,
+    failed_count INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP
 );
+
+CREATE TABLE login_history (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    email VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    outcome VARCHAR(20) CHECK (outcome IN ('success', 'failed', 'locked', 'unverified', 'mfa_pending', 'mfa_failed')) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_history_user ON login_history(user_id, created_at);
+
+CREATE TABLE user_mfa (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    confirmed_at TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE mfa_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_mfa_recovery_codes_user ON mfa_recovery_codes(user_id);
+
+CREATE TABLE mfa_role_policies (
+    role VARCHAR(50) PRIMARY KEY CHECK (role IN ('student', 'instructor', 'admin')),
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE instructor_applications (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    bio TEXT NOT NULL,
+    credentials TEXT NOT NULL,
+    status VARCHAR(20) CHECK (status IN ('pending', 'approved', 'rejected')) NOT NULL DEFAULT 'pending',
+    reviewer_id INT REFERENCES users(id) ON DELETE SET NULL,
+    review_notes TEXT,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Satu user hanya boleh punya satu pengajuan pending
+CREATE UNIQUE INDEX idx_instructor_applications_pending ON instructor_applications(user_id) WHERE status = 'pending';
+
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(32) UNIQUE NOT NULL,
+    key_hash VARCHAR(64) UNIQUE NOT NULL,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_user ON api_keys(user_id);
+
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    last_login_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX idx_user_identities_user ON user_identities(user_id);
+
+CREATE TABLE oidc_states (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE impersonation_audit_logs (
+    id SERIAL PRIMARY KEY,
+    token_id VARCHAR(64) NOT NULL,
+    impersonator_id INT REFERENCES users(id) ON DELETE SET NULL,
+    user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status_code INT NOT NULL,
+    reason TEXT,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_impersonation_audit_logs_token ON impersonation_audit_logs(token_id);
+CREATE INDEX idx_impersonation_audit_logs_impersonator ON impersonation_audit_logs(impersonator_id, created_at);
+CREATE INDEX idx_impersonation_audit_logs_user ON impersonation_audit_logs(user_id, created_at);
+
+CREATE TABLE sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    last_seen_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_user ON sessions(user_id, last_seen_at);
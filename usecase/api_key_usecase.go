@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/service"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Batas umur API key, 0 berarti tidak kedaluwarsa
+const maxAPIKeyLifetimeDays = 365
+
+type apiKeyUseCase struct {
+	repo          repository.APIKeyRepository
+	repoUser      repository.UserRepository
+	apiKeyService service.APIKeyService
+	userUseCase   UserUseCase
+}
+
+type APIKeyUseCase interface {
+	CreateAPIKey(userID int, key *model.APIKey, expiresInDays int, actor model.User) (model.APIKey, string, error)
+	GetAPIKeys(userID int, page modelutils.PageQuery, actor model.User) ([]model.APIKey, int64, error)
+	RevokeAPIKey(id int, actor model.User) error
+	CreateServiceAccount(account *model.User, actor model.User) (model.User, error)
+	GetServiceAccounts(page modelutils.PageQuery, actor model.User) ([]model.User, int64, error)
+}
+
+// CreateAPIKey => Key hanya ditampilkan sekali, yang disimpan hanya hash-nya
+func (a *apiKeyUseCase) CreateAPIKey(userID int, key *model.APIKey, expiresInDays int, actor model.User) (model.APIKey, string, error) {
+	owner, err := a.authorizeOwner(userID, actor)
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+
+	key.Name = strings.TrimSpace(key.Name)
+	if key.Name == "" || len(key.Scopes) == 0 {
+		return model.APIKey{}, "", apperror.BadRequest("name and scopes are required")
+	}
+
+	// Scope tidak boleh melebihi permission role pemilik key
+	scopes := make([]string, 0, len(key.Scopes))
+	seen := map[string]bool{}
+	for _, scope := range key.Scopes {
+		if !policy.Delegable(owner.Role, policy.Permission(scope)) {
+			return model.APIKey{}, "", apperror.BadRequest("invalid scope: %s", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if expiresInDays < 0 || expiresInDays > maxAPIKeyLifetimeDays {
+		return model.APIKey{}, "", apperror.BadRequest("invalid expiry")
+	}
+
+	rawKey, prefix, err := a.apiKeyService.GenerateAPIKey()
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+
+	key.UserID = owner.ID
+	key.Prefix = prefix
+	key.KeyHash = a.apiKeyService.HashAPIKey(rawKey)
+	key.Scopes = scopes
+	key.ExpiresAt = nil
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+	if expiresInDays > 0 {
+		expiresAt := time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour)
+		key.ExpiresAt = &expiresAt
+	}
+
+	newKey, err := a.repo.CreateAPIKey(key)
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+
+	return newKey, rawKey, nil
+}
+
+func (a *apiKeyUseCase) GetAPIKeys(userID int, page modelutils.PageQuery, actor model.User) ([]model.APIKey, int64, error) {
+	_, err := a.authorizeOwner(userID, actor)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return a.repo.GetAPIKeysByUserID(userID, page)
+}
+
+// RevokeAPIKey => Pemilik bisa mencabut key sendiri, admin bisa mencabut key siapa saja
+func (a *apiKeyUseCase) RevokeAPIKey(id int, actor model.User) error {
+	if id <= 0 {
+		return apperror.BadRequest("invalid api key id")
+	}
+
+	key, err := a.repo.GetAPIKeyById(id)
+	if err != nil {
+		return err
+	}
+
+	err = policy.Authorize(actor, policy.APIKeyManage, key.UserID == actor.ID)
+	if err != nil {
+		return err
+	}
+
+	return a.repo.RevokeAPIKey(id)
+}
+
+// CreateServiceAccount => Password acak yang tidak pernah diberikan, login hanya lewat API key
+func (a *apiKeyUseCase) CreateServiceAccount(account *model.User, actor model.User) (model.User, error) {
+	err := policy.Authorize(actor, policy.ServiceAccountManage, false)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	account.Name = strings.TrimSpace(account.Name)
+	if account.Name == "" {
+		return model.User{}, apperror.BadRequest("name is required")
+	}
+	if account.Role != "admin" && account.Role != "instructor" && account.Role != "student" {
+		return model.User{}, apperror.BadRequest("invalid role")
+	}
+
+	suffix := make([]byte, 8)
+	_, err = rand.Read(suffix)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	now := time.Now()
+	account.ID = 0
+	account.Email = fmt.Sprintf("svc-%s@service.edulearn.local", hex.EncodeToString(suffix))
+	account.AccountType = model.AccountTypeService
+	account.EmailVerifiedAt = &now
+
+	return a.userUseCase.CreateExternalUser(account)
+}
+
+func (a *apiKeyUseCase) GetServiceAccounts(page modelutils.PageQuery, actor model.User) ([]model.User, int64, error) {
+	err := policy.Authorize(actor, policy.ServiceAccountManage, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return a.repoUser.GetUsersByAccountType(model.AccountTypeService, page)
+}
+
+// authorizeOwner => Key user biasa hanya dikelola pemiliknya, key service account oleh admin
+func (a *apiKeyUseCase) authorizeOwner(userID int, actor model.User) (model.User, error) {
+	if userID <= 0 {
+		return model.User{}, apperror.BadRequest("invalid user id")
+	}
+
+	owner, err := a.repoUser.GetUserById(userID)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if owner.AccountType == model.AccountTypeService {
+		err = policy.Authorize(actor, policy.ServiceAccountManage, false)
+	} else if owner.ID == actor.ID {
+		err = policy.Authorize(actor, policy.APIKeyManage, true)
+	} else {
+		err = apperror.Forbidden(policy.ErrForbidden)
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return owner, nil
+}
+
+func NewAPIKeyUseCase(repo repository.APIKeyRepository, repoUser repository.UserRepository, apiKeyService service.APIKeyService, userUseCase UserUseCase) APIKeyUseCase {
+	return &apiKeyUseCase{repo: repo, repoUser: repoUser, apiKeyService: apiKeyService, userUseCase: userUseCase}
+}
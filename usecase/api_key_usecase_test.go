@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/service"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type fakeAPIKeyRepository struct {
+	repository.APIKeyRepository
+}
+
+func (fakeAPIKeyRepository) CreateAPIKey(key *model.APIKey) (model.APIKey, error) {
+	return *key, nil
+}
+
+func TestCreateAPIKeyScopeAndExpiry(t *testing.T) {
+	instructor := model.User{ID: 5, Role: "instructor"}
+
+	tests := []struct {
+		name          string
+		scopes        []string
+		expiresInDays int
+		wantErr       error
+		wantScopes    []string
+	}{
+		{"scopes within role", []string{"course:create", "material:read", "course:create"}, 30, nil, []string{"course:create", "material:read"}},
+		{"no expiry", []string{"course:create"}, 0, nil, []string{"course:create"}},
+		{"scope beyond role", []string{"course:create", string(policy.UserDelete)}, 30, apperror.ErrBadRequest, nil},
+		{"non delegable scope", []string{string(policy.APIKeyManage)}, 30, apperror.ErrBadRequest, nil},
+		{"unknown scope", []string{"course:everything"}, 30, apperror.ErrBadRequest, nil},
+		{"no scopes", nil, 30, apperror.ErrBadRequest, nil},
+		{"negative expiry", []string{"course:create"}, -1, apperror.ErrBadRequest, nil},
+		{"expiry over a year", []string{"course:create"}, 366, apperror.ErrBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase := NewAPIKeyUseCase(fakeAPIKeyRepository{}, &fakeUserRepository{user: instructor}, service.NewAPIKeyService(nil), nil)
+
+			key, rawKey, err := useCase.CreateAPIKey(5, &model.APIKey{Name: "ci", Scopes: pq.StringArray(tt.scopes)}, tt.expiresInDays, instructor)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateAPIKey error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateAPIKey: %v", err)
+			}
+
+			if !reflect.DeepEqual([]string(key.Scopes), tt.wantScopes) {
+				t.Errorf("scopes = %v, want %v", key.Scopes, tt.wantScopes)
+			}
+			if rawKey == "" || key.KeyHash == rawKey || key.UserID != 5 {
+				t.Errorf("key = %+v, want hashed key owned by user 5", key)
+			}
+
+			if tt.expiresInDays == 0 {
+				if key.ExpiresAt != nil {
+					t.Errorf("expires at = %v, want no expiry", key.ExpiresAt)
+				}
+				return
+			}
+			wantExpiry := time.Now().Add(time.Duration(tt.expiresInDays) * 24 * time.Hour)
+			if key.ExpiresAt == nil || key.ExpiresAt.Sub(wantExpiry).Abs() > time.Minute {
+				t.Errorf("expires at = %v, want about %v", key.ExpiresAt, wantExpiry)
+			}
+		})
+	}
+}
@@ -1,53 +1,599 @@
 package usecase
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"edu-learn/config"
 	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
 	"edu-learn/utils/service"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type authenticationUseCase struct {
-	userUseCase UserUseCase
-	jwtService  service.JwtService
+	userUseCase       UserUseCase
+	jwtService        service.JwtService
+	revocationService service.TokenRevocationService
+	repoRefreshToken  repository.RefreshTokenRepository
+	repoLoginAttempt  repository.LoginAttemptRepository
+	repoSession       repository.SessionRepository
+	emailVerification EmailVerificationUseCase
+	mfaUseCase        MFAUseCase
+	repoIdentity      repository.IdentityRepository
+	oidcProvider      service.OIDCProvider // nil => login OIDC nonaktif
+	cfg               config.Config
+
+	dummyPasswordHash []byte // dipakai saat email tidak terdaftar, cost sama dengan hash asli
 }
 
 type AuthenticationUseCase interface {
 	RegisterUseCase(user *model.User) (model.User, error)
-	LoginUseCase(email string, password string) (string, error)
+	LoginUseCase(email string, password string, ipAddress string, userAgent string) (modelutils.LoginResult, error)
+	OIDCLoginURLUseCase() (string, error)
+	OIDCCallbackUseCase(code string, state string, ipAddress string, userAgent string) (modelutils.LoginResult, error)
+	GetIdentities(userID int, page modelutils.PageQuery) ([]model.UserIdentity, int64, error)
+	LoginMFAUseCase(mfaToken string, code string, ipAddress string, userAgent string) (modelutils.TokenPair, error)
+	EnrollMFAUseCase(mfaToken string) (modelutils.MFAEnrollment, error)
+	ConfirmMFAUseCase(mfaToken string, code string, ipAddress string, userAgent string) (modelutils.TokenPair, []string, error)
+	RefreshUseCase(refreshToken string, ipAddress string, userAgent string) (modelutils.TokenPair, error)
+	LogoutUseCase(claim modelutils.JWTPayloadClaim, refreshToken string) error
+	UnlockAccount(userID int) error
+	GetLoginHistory(userID int, page modelutils.PageQuery) ([]model.LoginHistory, int64, error)
 }
 
 func (a *authenticationUseCase) RegisterUseCase(user *model.User) (model.User, error) {
-	if user.Role == "admin" || user.Role == "teacher" {
-		return model.User{}, fmt.Errorf("only student role is allowed for registration")
+	// Registrasi mandiri selalu sebagai siswa, instruktur lewat pengajuan yang disetujui admin
+	if user.Role == "" {
+		user.Role = "student"
+	}
+	if user.Role != "student" {
+		return model.User{}, apperror.BadRequest("only student role is allowed for registration")
+	}
+
+	// Status verifikasi dan jenis akun tidak boleh diisi sendiri oleh client
+	user.EmailVerifiedAt = nil
+	user.AccountType = model.AccountTypeUser
+
+	newUser, err := a.userUseCase.CreateUser(user)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	err = a.emailVerification.SendVerificationEmail(newUser)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to send verification email: %w", err)
 	}
 
-	return a.userUseCase.CreateUser(user)
+	return newUser, nil
 }
 
-func (a *authenticationUseCase) LoginUseCase(email string, password string) (string, error) {
+func (a *authenticationUseCase) LoginUseCase(email string, password string, ipAddress string, userAgent string) (modelutils.LoginResult, error) {
+	accountKey := "account:" + strings.ToLower(strings.TrimSpace(email))
+	ipKey := "ip:" + ipAddress
+
+	history := &model.LoginHistory{Email: email, IPAddress: ipAddress, UserAgent: userAgent}
+
+	lockedUntil, err := a.repoLoginAttempt.GetLockedUntil(accountKey, ipKey)
+	if err != nil {
+		return modelutils.LoginResult{}, err
+	}
+	if !lockedUntil.IsZero() {
+		history.Outcome = model.LoginOutcomeLocked
+		a.repoLoginAttempt.CreateLoginHistory(history)
+		return modelutils.LoginResult{}, apperror.TooManyRequests("too many login attempts")
+	}
+
 	// Dapat diubah jika user login dengan email
 	user, err := a.userUseCase.GetUserByEmail(email)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return modelutils.LoginResult{}, err
+	}
+
+	// Email tidak terdaftar tetap menjalankan bcrypt supaya waktu respon sama
+	hashedPassword := a.dummyPasswordHash
+	if err == nil {
+		hashedPassword = []byte(user.Password)
+		history.UserID = &user.ID
+	}
+
+	// Membandingkan hashed password dari database dengan password yang diterima
+	err = bcrypt.CompareHashAndPassword(hashedPassword, []byte(password))
+	// Service account tidak bisa login dengan password, hanya lewat API key
+	if err != nil || history.UserID == nil || user.AccountType == model.AccountTypeService {
+		// Pesan sama untuk email dan password salah supaya akun tidak bisa ditebak
+		history.Outcome = model.LoginOutcomeFailed
+		a.repoLoginAttempt.CreateLoginHistory(history)
+
+		_, err = a.repoLoginAttempt.RegisterFailure(accountKey, a.cfg.MaxLoginAttempts, a.cfg.LoginLockoutBase, a.cfg.LoginLockoutMax)
+		if err != nil {
+			return modelutils.LoginResult{}, err
+		}
+		_, err = a.repoLoginAttempt.RegisterFailure(ipKey, a.cfg.MaxLoginAttemptsPerIP, a.cfg.LoginLockoutBase, a.cfg.LoginLockoutMax)
+		if err != nil {
+			return modelutils.LoginResult{}, err
+		}
+
+		return modelutils.LoginResult{}, apperror.Unauthorized("invalid email or password")
+	}
+
+	// Dicek setelah password supaya status verifikasi tidak bocor ke orang lain
+	if a.cfg.EmailVerificationRequired && user.EmailVerifiedAt == nil {
+		history.Outcome = model.LoginOutcomeUnverified
+		a.repoLoginAttempt.CreateLoginHistory(history)
+		return modelutils.LoginResult{}, apperror.Forbidden("email not verified")
+	}
+
+	// Hitungan per IP tidak di-reset supaya login dengan akun sendiri tidak membuka lock IP
+	err = a.repoLoginAttempt.ResetFailures(accountKey)
+	if err != nil {
+		return modelutils.LoginResult{}, err
+	}
+
+	// Password asli hanya tersedia saat login, jadi di sini hash lama diganti ke BCRYPT_COST terbaru.
+	// Gagal rehash tidak menggagalkan login, dicoba lagi di login berikutnya
+	a.userUseCase.RehashPassword(user, password)
+
+	return a.completeLogin(user, history)
+}
+
+// completeLogin => Langkah terakhir login password maupun OIDC: minta kode MFA atau langsung terbitkan token
+func (a *authenticationUseCase) completeLogin(user model.User, history *model.LoginHistory) (modelutils.LoginResult, error) {
+	// Kredensial benar tapi masih butuh kode MFA, atau role wajib MFA tapi user belum mendaftar
+	mfaEnabled, err := a.mfaUseCase.IsEnabled(user.ID)
+	if err != nil {
+		return modelutils.LoginResult{}, err
+	}
+
+	mfaRequired := false
+	if !mfaEnabled {
+		mfaRequired, err = a.mfaUseCase.IsRequired(user.Role)
+		if err != nil {
+			return modelutils.LoginResult{}, err
+		}
+	}
+
+	if mfaEnabled || mfaRequired {
+		mfaToken, err := a.jwtService.CreateMFAToken(user)
+		if err != nil {
+			return modelutils.LoginResult{}, err
+		}
+
+		history.Outcome = model.LoginOutcomeMFAPending
+		a.repoLoginAttempt.CreateLoginHistory(history)
+
+		return modelutils.LoginResult{MFA: &modelutils.MFAChallenge{MFAToken: mfaToken, EnrollmentRequired: !mfaEnabled}}, nil
+	}
+
+	history.Outcome = model.LoginOutcomeSuccess
+	a.repoLoginAttempt.CreateLoginHistory(history)
+
+	tokens, err := a.startSession(user, history.IPAddress, history.UserAgent)
+	if err != nil {
+		return modelutils.LoginResult{}, err
+	}
+
+	return modelutils.LoginResult{Tokens: tokens}, nil
+}
+
+// OIDCLoginURLUseCase => Mulai authorization code flow, state, nonce, dan code_verifier disimpan sampai callback
+func (a *authenticationUseCase) OIDCLoginURLUseCase() (string, error) {
+	if a.oidcProvider == nil {
+		return "", apperror.NotFound("oidc login is not enabled")
+	}
+
+	state, err := newSecureToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := newSecureToken()
+	if err != nil {
+		return "", err
+	}
+	codeVerifier, err := service.NewPKCEVerifier()
 	if err != nil {
 		return "", err
 	}
 
-	// Membandingkan hashed password dari database dengan password yang diterima
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	_, err = a.repoIdentity.CreateOIDCState(&model.OIDCState{
+		StateHash:    hashSecureToken(state),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(a.cfg.OIDCStateLifeTime),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return a.oidcProvider.AuthCodeURL(state, nonce, service.PKCEChallenge(codeVerifier))
+}
+
+// OIDCCallbackUseCase => Tukar code dengan ID token lalu login sebagai user yang terhubung dengan identitas tersebut
+func (a *authenticationUseCase) OIDCCallbackUseCase(code string, state string, ipAddress string, userAgent string) (modelutils.LoginResult, error) {
+	if a.oidcProvider == nil {
+		return modelutils.LoginResult{}, apperror.NotFound("oidc login is not enabled")
+	}
+
+	if code == "" || state == "" {
+		return modelutils.LoginResult{}, apperror.Unauthorized("invalid oidc state")
+	}
+
+	stored, err := a.repoIdentity.ConsumeOIDCState(hashSecureToken(state))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return modelutils.LoginResult{}, apperror.Unauthorized("invalid oidc state")
+		}
+		return modelutils.LoginResult{}, err
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return modelutils.LoginResult{}, apperror.Unauthorized("invalid oidc state")
+	}
+
+	identity, err := a.oidcProvider.Exchange(code, stored.CodeVerifier)
+	if err != nil {
+		return modelutils.LoginResult{}, apperror.Unauthorized("oidc login failed")
+	}
+	if subtle.ConstantTimeCompare([]byte(identity.Nonce), []byte(stored.Nonce)) != 1 {
+		return modelutils.LoginResult{}, apperror.Unauthorized("oidc login failed")
+	}
+
+	history := &model.LoginHistory{Email: identity.Email, IPAddress: ipAddress, UserAgent: userAgent}
+
+	user, identityID, err := a.resolveIdentity(identity)
+	if err != nil {
+		return modelutils.LoginResult{}, err
+	}
+	history.UserID = &user.ID
+
+	if user.AccountType == model.AccountTypeService {
+		history.Outcome = model.LoginOutcomeFailed
+		a.repoLoginAttempt.CreateLoginHistory(history)
+		return modelutils.LoginResult{}, apperror.Unauthorized("oidc login failed")
+	}
+
+	if a.cfg.EmailVerificationRequired && user.EmailVerifiedAt == nil {
+		history.Outcome = model.LoginOutcomeUnverified
+		a.repoLoginAttempt.CreateLoginHistory(history)
+		return modelutils.LoginResult{}, apperror.Forbidden("email not verified")
+	}
+
+	err = a.repoIdentity.TouchIdentity(identityID, time.Now())
+	if err != nil {
+		return modelutils.LoginResult{}, err
+	}
+
+	return a.completeLogin(user, history)
+}
+
+// resolveIdentity => Cari user dari identitas, hubungkan lewat email terverifikasi, atau buat user baru
+func (a *authenticationUseCase) resolveIdentity(identity modelutils.OIDCIdentity) (model.User, int, error) {
+	provider := a.oidcProvider.Name()
+
+	existing, err := a.repoIdentity.GetIdentity(provider, identity.Subject)
+	if err == nil && existing.User != nil {
+		return *existing.User, existing.ID, nil
+	}
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return model.User{}, 0, err
+	}
+
+	if identity.Email == "" {
+		return model.User{}, 0, apperror.BadRequest("oidc provider did not return an email")
+	}
+
+	user, err := a.userUseCase.GetUserByEmail(identity.Email)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return model.User{}, 0, err
+	}
+
+	if err == nil {
+		// Akun lokal hanya dihubungkan jika email terverifikasi di kedua sisi,
+		// supaya orang lain tidak bisa mengambil alih akun lewat email yang belum terbukti miliknya
+		if !identity.EmailVerified || user.EmailVerifiedAt == nil {
+			return model.User{}, 0, apperror.Conflict("email already registered")
+		}
+	} else {
+		user, err = a.createOIDCUser(identity)
+		if err != nil {
+			return model.User{}, 0, err
+		}
+	}
+
+	linked, err := a.repoIdentity.CreateIdentity(&model.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return model.User{}, 0, err
+	}
+
+	return user, linked.ID, nil
+}
+
+// createOIDCUser => User baru dari OIDC selalu student dengan password acak yang tidak pernah diberikan
+func (a *authenticationUseCase) createOIDCUser(identity modelutils.OIDCIdentity) (model.User, error) {
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+
+	user := &model.User{
+		Name:        name,
+		Email:       identity.Email,
+		Role:        "student",
+		AccountType: model.AccountTypeUser,
+	}
+	if identity.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	newUser, err := a.userUseCase.CreateExternalUser(user)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if newUser.EmailVerifiedAt == nil {
+		err = a.emailVerification.SendVerificationEmail(newUser)
+		if err != nil {
+			return model.User{}, fmt.Errorf("failed to send verification email: %w", err)
+		}
+	}
+
+	return newUser, nil
+}
+
+func (a *authenticationUseCase) GetIdentities(userID int, page modelutils.PageQuery) ([]model.UserIdentity, int64, error) {
+	if userID == 0 {
+		return nil, 0, apperror.BadRequest("id are required")
+	}
+
+	return a.repoIdentity.GetIdentitiesByUserID(userID, page)
+}
+
+// LoginMFAUseCase => Langkah kedua login, tukar token MFA dan kode TOTP/recovery code dengan token asli
+func (a *authenticationUseCase) LoginMFAUseCase(mfaToken string, code string, ipAddress string, userAgent string) (modelutils.TokenPair, error) {
+	claim, user, err := a.verifyMFAToken(mfaToken)
 	if err != nil {
-		// Jika password tidak cocok
-		return "", fmt.Errorf("password incorrect")
+		return modelutils.TokenPair{}, err
 	}
 
-	token, err := a.jwtService.CreateToken(user)
+	history := &model.LoginHistory{UserID: &user.ID, Email: user.Email, IPAddress: ipAddress, UserAgent: userAgent}
+
+	err = a.mfaUseCase.VerifyCode(user.ID, code)
+	if err != nil {
+		history.Outcome = model.LoginOutcomeMFAFailed
+		a.repoLoginAttempt.CreateLoginHistory(history)
+		return modelutils.TokenPair{}, err
+	}
+
+	history.Outcome = model.LoginOutcomeSuccess
+	a.repoLoginAttempt.CreateLoginHistory(history)
+
+	return a.finishMFALogin(claim, user, ipAddress, userAgent)
+}
+
+// EnrollMFAUseCase => Untuk user yang role-nya wajib MFA tapi belum mendaftar saat login
+func (a *authenticationUseCase) EnrollMFAUseCase(mfaToken string) (modelutils.MFAEnrollment, error) {
+	_, user, err := a.verifyMFAToken(mfaToken)
+	if err != nil {
+		return modelutils.MFAEnrollment{}, err
+	}
+
+	return a.mfaUseCase.Enroll(user.ID)
+}
+
+func (a *authenticationUseCase) ConfirmMFAUseCase(mfaToken string, code string, ipAddress string, userAgent string) (modelutils.TokenPair, []string, error) {
+	claim, user, err := a.verifyMFAToken(mfaToken)
+	if err != nil {
+		return modelutils.TokenPair{}, nil, err
+	}
+
+	recoveryCodes, err := a.mfaUseCase.ConfirmEnrollment(user.ID, code)
+	if err != nil {
+		return modelutils.TokenPair{}, nil, err
+	}
+
+	a.repoLoginAttempt.CreateLoginHistory(&model.LoginHistory{
+		UserID:    &user.ID,
+		Email:     user.Email,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Outcome:   model.LoginOutcomeSuccess,
+	})
+
+	tokens, err := a.finishMFALogin(claim, user, ipAddress, userAgent)
+	if err != nil {
+		return modelutils.TokenPair{}, nil, err
+	}
+
+	return tokens, recoveryCodes, nil
+}
+
+// UnlockAccount => Admin membuka lock akun sebelum waktunya habis
+func (a *authenticationUseCase) UnlockAccount(userID int) error {
+	user, err := a.userUseCase.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+
+	return a.repoLoginAttempt.ResetFailures("account:" + strings.ToLower(strings.TrimSpace(user.Email)))
+}
+
+func (a *authenticationUseCase) GetLoginHistory(userID int, page modelutils.PageQuery) ([]model.LoginHistory, int64, error) {
+	if userID == 0 {
+		return nil, 0, apperror.BadRequest("id are required")
+	}
+
+	return a.repoLoginAttempt.GetLoginHistoryByUserID(userID, page)
+}
+
+func (a *authenticationUseCase) RefreshUseCase(refreshToken string, ipAddress string, userAgent string) (modelutils.TokenPair, error) {
+	stored, err := a.repoRefreshToken.GetRefreshTokenByHash(a.jwtService.HashRefreshToken(refreshToken))
+	if err != nil {
+		return modelutils.TokenPair{}, apperror.Unauthorized("invalid refresh token")
+	}
+
+	if stored.RevokedAt != nil {
+		return modelutils.TokenPair{}, apperror.Unauthorized("invalid refresh token")
+	}
+
+	// Token yang sudah dirotasi dipakai lagi, kemungkinan dicuri: cabut seluruh sesi
+	if stored.UsedAt != nil {
+		a.revocationService.RevokeSession(stored.FamilyID)
+		return modelutils.TokenPair{}, repository.ErrRefreshTokenReuse
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return modelutils.TokenPair{}, apperror.Unauthorized("refresh token expired")
+	}
+
+	user, err := a.userUseCase.GetUserById(stored.UserID)
+	if err != nil {
+		return modelutils.TokenPair{}, apperror.Unauthorized("invalid refresh token")
+	}
+
+	return a.issueTokenPair(user, stored.FamilyID, func(newToken *model.RefreshToken) error {
+		_, err := a.repoRefreshToken.RotateRefreshToken(stored.ID, newToken)
+		if err != nil {
+			if errors.Is(err, repository.ErrRefreshTokenReuse) {
+				a.revocationService.RevokeSession(stored.FamilyID)
+			}
+			return err
+		}
+
+		// Refresh dianggap aktivitas terakhir sesi
+		return a.repoSession.TouchSession(stored.FamilyID, ipAddress, userAgent, newToken.ExpiresAt)
+	})
+}
+
+func (a *authenticationUseCase) LogoutUseCase(claim modelutils.JWTPayloadClaim, refreshToken string) error {
+	err := a.revocationService.RevokeToken(claim)
+	if err != nil {
+		return err
+	}
+
+	// Logout mengakhiri sesi token ini beserta refresh token-nya
+	if claim.SessionID != "" {
+		err = a.revocationService.RevokeSession(claim.SessionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Refresh token opsional, jika dikirim seluruh family ikut dicabut
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := a.repoRefreshToken.GetRefreshTokenByHash(a.jwtService.HashRefreshToken(refreshToken))
+	if err != nil || stored.UserID != claim.UserId || stored.FamilyID == claim.SessionID {
+		return nil
+	}
+
+	return a.revocationService.RevokeSession(stored.FamilyID)
+}
+
+func (a *authenticationUseCase) verifyMFAToken(mfaToken string) (modelutils.JWTPayloadClaim, model.User, error) {
+	claim, err := a.jwtService.VerifyMFAToken(mfaToken)
+	if err != nil {
+		return modelutils.JWTPayloadClaim{}, model.User{}, apperror.Unauthorized("invalid mfa token")
+	}
+
+	revoked, err := a.revocationService.IsRevoked(claim)
+	if err != nil || revoked {
+		return modelutils.JWTPayloadClaim{}, model.User{}, apperror.Unauthorized("invalid mfa token")
+	}
+
+	user, err := a.userUseCase.GetUserById(claim.UserId)
+	if err != nil {
+		return modelutils.JWTPayloadClaim{}, model.User{}, apperror.Unauthorized("invalid mfa token")
+	}
+
+	return claim, user, nil
+}
+
+// finishMFALogin => Token MFA hanya bisa ditukar sekali
+func (a *authenticationUseCase) finishMFALogin(claim modelutils.JWTPayloadClaim, user model.User, ipAddress string, userAgent string) (modelutils.TokenPair, error) {
+	err := a.revocationService.RevokeToken(claim)
+	if err != nil {
+		return modelutils.TokenPair{}, err
+	}
+
+	return a.startSession(user, ipAddress, userAgent)
+}
+
+// startSession => Setiap login memulai sesi baru, ID sesi dipakai sebagai family refresh token
+func (a *authenticationUseCase) startSession(user model.User, ipAddress string, userAgent string) (modelutils.TokenPair, error) {
+	sessionID, err := newFamilyID()
+	if err != nil {
+		return modelutils.TokenPair{}, err
+	}
+
+	return a.issueTokenPair(user, sessionID, func(refreshToken *model.RefreshToken) error {
+		_, err := a.repoSession.CreateSession(&model.Session{
+			ID:         sessionID,
+			UserID:     user.ID,
+			IPAddress:  ipAddress,
+			UserAgent:  userAgent,
+			LastSeenAt: time.Now(),
+			ExpiresAt:  refreshToken.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		refreshToken.UserID = user.ID
+		refreshToken.FamilyID = sessionID
+		_, err = a.repoRefreshToken.CreateRefreshToken(refreshToken)
+		return err
+	})
+}
+
+// issueTokenPair => Buat access token dan refresh token, save menyimpan refresh token ke database
+func (a *authenticationUseCase) issueTokenPair(user model.User, sessionID string, save func(refreshToken *model.RefreshToken) error) (modelutils.TokenPair, error) {
+	accessToken, err := a.jwtService.CreateToken(user, sessionID)
+	if err != nil {
+		return modelutils.TokenPair{}, err
+	}
+
+	refreshToken, expiresAt, err := a.jwtService.CreateRefreshToken()
+	if err != nil {
+		return modelutils.TokenPair{}, err
+	}
+
+	err = save(&model.RefreshToken{
+		TokenHash: a.jwtService.HashRefreshToken(refreshToken),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return modelutils.TokenPair{}, err
+	}
+
+	return modelutils.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+func newFamilyID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
 	if err != nil {
 		return "", err
 	}
 
-	return token, nil
+	return hex.EncodeToString(b), nil
 }
 
-func NewAuthenticationUsecase(uc UserUseCase, jwtService service.JwtService) AuthenticationUseCase {
-	return &authenticationUseCase{userUseCase: uc, jwtService: jwtService}
+func NewAuthenticationUsecase(uc UserUseCase, jwtService service.JwtService, revocationService service.TokenRevocationService, repoRefreshToken repository.RefreshTokenRepository, repoLoginAttempt repository.LoginAttemptRepository, repoSession repository.SessionRepository, emailVerification EmailVerificationUseCase, mfaUseCase MFAUseCase, repoIdentity repository.IdentityRepository, oidcProvider service.OIDCProvider, cfg config.Config) AuthenticationUseCase {
+	dummyPasswordHash, _ := bcrypt.GenerateFromPassword([]byte("edu-learn-dummy-password"), cfg.BcryptCost)
+
+	return &authenticationUseCase{userUseCase: uc, jwtService: jwtService, revocationService: revocationService, repoRefreshToken: repoRefreshToken, repoLoginAttempt: repoLoginAttempt, repoSession: repoSession, emailVerification: emailVerification, mfaUseCase: mfaUseCase, repoIdentity: repoIdentity, oidcProvider: oidcProvider, cfg: cfg, dummyPasswordHash: dummyPasswordHash}
 }
@@ -0,0 +1,594 @@
+package usecase
+
+import (
+	"edu-learn/config"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/service"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeIdentityRepository => State dihapus saat dibaca, sama seperti ConsumeOIDCState di database
+type fakeIdentityRepository struct {
+	repository.IdentityRepository
+	states     map[string]model.OIDCState
+	identities []model.UserIdentity
+	users      *fakeUserUseCase
+}
+
+func (f *fakeIdentityRepository) CreateOIDCState(state *model.OIDCState) (model.OIDCState, error) {
+	f.states[state.StateHash] = *state
+	return *state, nil
+}
+
+func (f *fakeIdentityRepository) ConsumeOIDCState(stateHash string) (model.OIDCState, error) {
+	state, ok := f.states[stateHash]
+	if !ok {
+		return model.OIDCState{}, apperror.NotFound("oidc state not found")
+	}
+	delete(f.states, stateHash)
+	return state, nil
+}
+
+func (f *fakeIdentityRepository) GetIdentity(provider string, subject string) (model.UserIdentity, error) {
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			user := f.users.byID(identity.UserID)
+			identity.User = &user
+			return identity, nil
+		}
+	}
+	return model.UserIdentity{}, apperror.NotFound("identity not found")
+}
+
+func (f *fakeIdentityRepository) CreateIdentity(identity *model.UserIdentity) (model.UserIdentity, error) {
+	identity.ID = len(f.identities) + 1
+	f.identities = append(f.identities, *identity)
+	return *identity, nil
+}
+
+func (f *fakeIdentityRepository) TouchIdentity(id int, loginAt time.Time) error {
+	return nil
+}
+
+type fakeUserUseCase struct {
+	UserUseCase
+	users []model.User
+}
+
+func (f *fakeUserUseCase) byID(id int) model.User {
+	for _, user := range f.users {
+		if user.ID == id {
+			return user
+		}
+	}
+	return model.User{}
+}
+
+func (f *fakeUserUseCase) GetUserByEmail(email string) (model.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return model.User{}, apperror.NotFound("user not found")
+}
+
+func (f *fakeUserUseCase) CreateExternalUser(user *model.User) (model.User, error) {
+	user.ID = len(f.users) + 1
+	f.users = append(f.users, *user)
+	return *user, nil
+}
+
+type fakeMFAUseCase struct {
+	MFAUseCase
+}
+
+func (fakeMFAUseCase) IsEnabled(userID int) (bool, error) {
+	return false, nil
+}
+
+func (fakeMFAUseCase) IsRequired(role string) (bool, error) {
+	return false, nil
+}
+
+type fakeEmailVerificationUseCase struct {
+	EmailVerificationUseCase
+	sent []string
+}
+
+func (f *fakeEmailVerificationUseCase) SendVerificationEmail(user model.User) error {
+	f.sent = append(f.sent, user.Email)
+	return nil
+}
+
+type fakeLoginAttemptRepository struct {
+	repository.LoginAttemptRepository
+}
+
+func (fakeLoginAttemptRepository) CreateLoginHistory(history *model.LoginHistory) (model.LoginHistory, error) {
+	return *history, nil
+}
+
+type fakeSessionRepository struct {
+	repository.SessionRepository
+}
+
+func (fakeSessionRepository) CreateSession(session *model.Session) (model.Session, error) {
+	return *session, nil
+}
+
+type fakeRefreshTokenRepository struct {
+	repository.RefreshTokenRepository
+}
+
+func (fakeRefreshTokenRepository) CreateRefreshToken(token *model.RefreshToken) (model.RefreshToken, error) {
+	return *token, nil
+}
+
+type oidcTestEnv struct {
+	useCase    AuthenticationUseCase
+	stub       *service.StubOIDCProvider
+	identities *fakeIdentityRepository
+	users      *fakeUserUseCase
+	verifier   *fakeEmailVerificationUseCase
+}
+
+func newOIDCTestEnv(users ...model.User) *oidcTestEnv {
+	userUseCase := &fakeUserUseCase{users: users}
+	identities := &fakeIdentityRepository{states: map[string]model.OIDCState{}, users: userUseCase}
+	verifier := &fakeEmailVerificationUseCase{}
+	stub := service.NewStubOIDCProvider("http://localhost/api/oidc/stub/authorize", "http://localhost/api/oidc/callback")
+
+	cfg := config.Config{
+		TokenConfig: config.TokenConfig{
+			ApplicationName:      "edu-learn-test",
+			JWTSignatureKey:      []byte("test-signature-key"),
+			JWTSigningMethod:     jwt.SigningMethodHS256,
+			AccessTokenLifeTime:  time.Minute,
+			RefreshTokenLifeTime: time.Hour,
+		},
+		OIDCConfig:     config.OIDCConfig{OIDCStateLifeTime: time.Minute},
+		PasswordConfig: config.PasswordConfig{BcryptCost: bcrypt.MinCost},
+	}
+
+	useCase := NewAuthenticationUsecase(userUseCase, service.NewJwtService(cfg.TokenConfig), nil, fakeRefreshTokenRepository{}, fakeLoginAttemptRepository{}, fakeSessionRepository{}, verifier, fakeMFAUseCase{}, identities, stub, cfg)
+
+	return &oidcTestEnv{useCase: useCase, stub: stub, identities: identities, users: userUseCase, verifier: verifier}
+}
+
+// oidcAuthRequest => Parameter yang dikirim ke halaman login provider
+type oidcAuthRequest struct {
+	state         string
+	nonce         string
+	codeChallenge string
+}
+
+func (e *oidcTestEnv) startLogin(t *testing.T) oidcAuthRequest {
+	t.Helper()
+
+	loginURL, err := e.useCase.OIDCLoginURLUseCase()
+	if err != nil {
+		t.Fatalf("OIDCLoginURLUseCase: %v", err)
+	}
+
+	parsed, err := url.Parse(loginURL)
+	if err != nil {
+		t.Fatalf("parse login url: %v", err)
+	}
+	query := parsed.Query()
+
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("code_challenge_method = %q, want S256", query.Get("code_challenge_method"))
+	}
+
+	return oidcAuthRequest{state: query.Get("state"), nonce: query.Get("nonce"), codeChallenge: query.Get("code_challenge")}
+}
+
+// authorize => Login di provider stub, mengembalikan code dari URL callback
+func (e *oidcTestEnv) authorize(t *testing.T, email string, emailVerified bool, request oidcAuthRequest) string {
+	t.Helper()
+
+	callbackURL, err := e.stub.Authorize(email, "Budi", emailVerified, request.state, request.nonce, request.codeChallenge)
+	if err != nil {
+		t.Fatalf("stub authorize: %v", err)
+	}
+
+	parsed, err := url.Parse(callbackURL)
+	if err != nil {
+		t.Fatalf("parse callback url: %v", err)
+	}
+	if got := parsed.Query().Get("state"); got != request.state {
+		t.Fatalf("callback state = %q, want %q", got, request.state)
+	}
+
+	return parsed.Query().Get("code")
+}
+
+func (e *oidcTestEnv) callback(code string, state string) error {
+	_, err := e.useCase.OIDCCallbackUseCase(code, state, "127.0.0.1", "go-test")
+	return err
+}
+
+func TestOIDCCallbackRejectsInvalidFlow(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, env *oidcTestEnv) error
+	}{
+		{"wrong state", func(t *testing.T, env *oidcTestEnv) error {
+			request := env.startLogin(t)
+			code := env.authorize(t, "budi@example.com", true, request)
+			return env.callback(code, "state-from-another-login")
+		}},
+		{"reused state", func(t *testing.T, env *oidcTestEnv) error {
+			request := env.startLogin(t)
+			code := env.authorize(t, "budi@example.com", true, request)
+			if err := env.callback(code, request.state); err != nil {
+				t.Fatalf("first callback: %v", err)
+			}
+
+			// State sudah terpakai, code baru untuk state yang sama tetap ditolak
+			code = env.authorize(t, "budi@example.com", true, request)
+			return env.callback(code, request.state)
+		}},
+		{"wrong pkce verifier", func(t *testing.T, env *oidcTestEnv) error {
+			request := env.startLogin(t)
+			request.codeChallenge = service.PKCEChallenge("verifier-of-the-attacker-aaaaaaaaaaaaaaaaaaa")
+			code := env.authorize(t, "budi@example.com", true, request)
+			return env.callback(code, request.state)
+		}},
+		{"reused code", func(t *testing.T, env *oidcTestEnv) error {
+			request := env.startLogin(t)
+			code := env.authorize(t, "budi@example.com", true, request)
+			if err := env.callback(code, request.state); err != nil {
+				t.Fatalf("first callback: %v", err)
+			}
+
+			// Code yang sama diputar ulang dengan state baru yang sah
+			return env.callback(code, env.startLogin(t).state)
+		}},
+		{"nonce mismatch", func(t *testing.T, env *oidcTestEnv) error {
+			request := env.startLogin(t)
+			request.nonce = "nonce-from-another-login"
+			code := env.authorize(t, "budi@example.com", true, request)
+			return env.callback(code, request.state)
+		}},
+		{"empty code", func(t *testing.T, env *oidcTestEnv) error {
+			return env.callback("", env.startLogin(t).state)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv()
+
+			err := tt.run(t, env)
+			if !errors.Is(err, apperror.ErrUnauthorized) {
+				t.Errorf("callback error = %v, want unauthorized", err)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackLinksVerifiedEmail(t *testing.T) {
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name          string
+		localVerified bool
+		emailVerified bool
+		wantLinked    bool
+	}{
+		{"both verified", true, true, true},
+		{"provider email not verified", true, false, false},
+		{"local email not verified", false, true, false},
+		{"neither verified", false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local := model.User{ID: 1, Name: "Budi", Email: "budi@example.com", Role: "student", AccountType: model.AccountTypeUser}
+			if tt.localVerified {
+				local.EmailVerifiedAt = &verifiedAt
+			}
+			env := newOIDCTestEnv(local)
+
+			request := env.startLogin(t)
+			code := env.authorize(t, local.Email, tt.emailVerified, request)
+			err := env.callback(code, request.state)
+
+			if tt.wantLinked {
+				if err != nil {
+					t.Fatalf("callback: %v", err)
+				}
+				if len(env.identities.identities) != 1 || env.identities.identities[0].UserID != local.ID {
+					t.Errorf("identities = %+v, want one linked to user %d", env.identities.identities, local.ID)
+				}
+			} else {
+				if !errors.Is(err, apperror.ErrConflict) {
+					t.Errorf("callback error = %v, want conflict", err)
+				}
+				if len(env.identities.identities) != 0 {
+					t.Errorf("identity was linked to an unverified account: %+v", env.identities.identities)
+				}
+			}
+
+			if len(env.users.users) != 1 {
+				t.Errorf("users = %d, want the existing user only", len(env.users.users))
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackCreatesUser(t *testing.T) {
+	tests := []struct {
+		name          string
+		emailVerified bool
+		wantEmailSent bool
+	}{
+		{"verified email", true, false},
+		{"unverified email gets verification email", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv()
+
+			request := env.startLogin(t)
+			code := env.authorize(t, "siti@example.com", tt.emailVerified, request)
+			result, err := env.useCase.OIDCCallbackUseCase(code, request.state, "127.0.0.1", "go-test")
+			if err != nil {
+				t.Fatalf("callback: %v", err)
+			}
+			if result.Tokens.AccessToken == "" {
+				t.Errorf("login did not issue an access token")
+			}
+
+			if len(env.users.users) != 1 {
+				t.Fatalf("users = %d, want 1", len(env.users.users))
+			}
+			user := env.users.users[0]
+			if user.Email != "siti@example.com" || user.Role != "student" {
+				t.Errorf("created user = %+v, want student siti@example.com", user)
+			}
+			if (user.EmailVerifiedAt != nil) != tt.emailVerified {
+				t.Errorf("email verified = %v, want %v", user.EmailVerifiedAt != nil, tt.emailVerified)
+			}
+			if sent := len(env.verifier.sent) == 1; sent != tt.wantEmailSent {
+				t.Errorf("verification email sent = %v, want %v", sent, tt.wantEmailSent)
+			}
+
+			if len(env.identities.identities) != 1 || env.identities.identities[0].UserID != user.ID {
+				t.Fatalf("identities = %+v, want one linked to user %d", env.identities.identities, user.ID)
+			}
+
+			// Login berikutnya memakai identitas yang sudah terhubung, bukan membuat user baru
+			request = env.startLogin(t)
+			code = env.authorize(t, "siti@example.com", tt.emailVerified, request)
+			if err := env.callback(code, request.state); err != nil {
+				t.Fatalf("second callback: %v", err)
+			}
+			if len(env.users.users) != 1 || len(env.identities.identities) != 1 {
+				t.Errorf("second login created users=%d identities=%d, want 1 and 1", len(env.users.users), len(env.identities.identities))
+			}
+		})
+	}
+}
+
+func (f *fakeUserUseCase) GetUserById(id int) (model.User, error) {
+	user := f.byID(id)
+	if user.ID == 0 {
+		return model.User{}, apperror.NotFound("user not found")
+	}
+	return user, nil
+}
+
+func (fakeSessionRepository) TouchSession(id string, ipAddress string, userAgent string, expiresAt time.Time) error {
+	return nil
+}
+
+// memoryRefreshTokenRepository => Meniru RotateRefreshToken: token lama ditandai dipakai dan token yang sudah dipakai ditolak.
+// staleReads meniru request paralel yang membaca token sebelum request lain merotasinya
+type memoryRefreshTokenRepository struct {
+	repository.RefreshTokenRepository
+	tokens     []model.RefreshToken
+	staleReads bool
+}
+
+func (m *memoryRefreshTokenRepository) GetRefreshTokenByHash(hash string) (model.RefreshToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == hash {
+			if m.staleReads {
+				token.UsedAt = nil
+			}
+			return token, nil
+		}
+	}
+	return model.RefreshToken{}, apperror.NotFound("refresh token not found")
+}
+
+func (m *memoryRefreshTokenRepository) RotateRefreshToken(id int, newToken *model.RefreshToken) (model.RefreshToken, error) {
+	oldToken := &m.tokens[id-1]
+	if oldToken.UsedAt != nil || oldToken.RevokedAt != nil {
+		return model.RefreshToken{}, repository.ErrRefreshTokenReuse
+	}
+
+	now := time.Now()
+	oldToken.UsedAt = &now
+
+	newToken.ID = len(m.tokens) + 1
+	newToken.UserID = oldToken.UserID
+	newToken.FamilyID = oldToken.FamilyID
+	m.tokens = append(m.tokens, *newToken)
+	return *newToken, nil
+}
+
+type recordingRevocationService struct {
+	service.TokenRevocationService
+	sessions []string
+}
+
+func (r *recordingRevocationService) RevokeSession(sessionID string) error {
+	r.sessions = append(r.sessions, sessionID)
+	return nil
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		staleReads bool
+	}{
+		{"rotated token used again", false},
+		{"parallel refresh with the same token", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{
+				TokenConfig: config.TokenConfig{
+					JWTSignatureKey:      []byte("test-signature-key"),
+					JWTSigningMethod:     jwt.SigningMethodHS256,
+					AccessTokenLifeTime:  time.Minute,
+					RefreshTokenLifeTime: time.Hour,
+				},
+				PasswordConfig: config.PasswordConfig{BcryptCost: bcrypt.MinCost},
+			}
+			jwtService := service.NewJwtService(cfg.TokenConfig)
+			users := &fakeUserUseCase{users: []model.User{{ID: 1, Email: "budi@example.com", Role: "student"}}}
+			refreshTokens := &memoryRefreshTokenRepository{tokens: []model.RefreshToken{{
+				ID:        1,
+				UserID:    1,
+				FamilyID:  "family-1",
+				TokenHash: jwtService.HashRefreshToken("refresh-1"),
+				ExpiresAt: time.Now().Add(time.Hour),
+			}}}
+			revocation := &recordingRevocationService{}
+			useCase := NewAuthenticationUsecase(users, jwtService, revocation, refreshTokens, fakeLoginAttemptRepository{}, fakeSessionRepository{}, nil, fakeMFAUseCase{}, nil, nil, cfg)
+
+			tokens, err := useCase.RefreshUseCase("refresh-1", "127.0.0.1", "go-test")
+			if err != nil {
+				t.Fatalf("first refresh: %v", err)
+			}
+			if tokens.RefreshToken == "" || tokens.RefreshToken == "refresh-1" {
+				t.Fatalf("first refresh did not rotate the refresh token")
+			}
+			if len(revocation.sessions) != 0 {
+				t.Fatalf("first refresh revoked sessions %v", revocation.sessions)
+			}
+
+			refreshTokens.staleReads = tt.staleReads
+			_, err = useCase.RefreshUseCase("refresh-1", "127.0.0.1", "go-test")
+			if !errors.Is(err, repository.ErrRefreshTokenReuse) {
+				t.Fatalf("reuse error = %v, want refresh token reuse", err)
+			}
+			if len(revocation.sessions) != 1 || revocation.sessions[0] != "family-1" {
+				t.Errorf("revoked sessions = %v, want [family-1]", revocation.sessions)
+			}
+		})
+	}
+}
+
+func (f *fakeUserUseCase) RehashPassword(user model.User, password string) error {
+	return nil
+}
+
+func newLoginTestUseCase(t *testing.T, attempts repository.LoginAttemptRepository) AuthenticationUseCase {
+	hash, err := bcrypt.GenerateFromPassword([]byte("Rahasia123!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+
+	cfg := config.Config{
+		TokenConfig: config.TokenConfig{
+			JWTSignatureKey:      []byte("test-signature-key"),
+			JWTSigningMethod:     jwt.SigningMethodHS256,
+			AccessTokenLifeTime:  time.Minute,
+			RefreshTokenLifeTime: time.Hour,
+		},
+		LoginConfig:    config.LoginConfig{MaxLoginAttempts: 3, MaxLoginAttemptsPerIP: 5, LoginLockoutBase: time.Minute, LoginLockoutMax: time.Hour},
+		PasswordConfig: config.PasswordConfig{BcryptCost: bcrypt.MinCost},
+	}
+	users := &fakeUserUseCase{users: []model.User{
+		{ID: 1, Email: "budi@example.com", Password: string(hash), Role: "student"},
+		{ID: 2, Email: "siti@example.com", Password: string(hash), Role: "student"},
+	}}
+
+	return NewAuthenticationUsecase(users, service.NewJwtService(cfg.TokenConfig), nil, fakeRefreshTokenRepository{}, attempts, fakeSessionRepository{}, nil, fakeMFAUseCase{}, nil, nil, cfg)
+}
+
+func TestLoginLocksAccountAfterFailedAttempts(t *testing.T) {
+	attempts := newMemoryLoginAttemptRepository()
+	useCase := newLoginTestUseCase(t, attempts)
+
+	for i := 0; i < 3; i++ {
+		_, err := useCase.LoginUseCase("budi@example.com", "salah", "10.0.0.1", "go-test")
+		if !errors.Is(err, apperror.ErrUnauthorized) {
+			t.Fatalf("attempt %d error = %v, want unauthorized", i+1, err)
+		}
+	}
+
+	// Password benar tetap ditolak selama akun terkunci, email ditulis beda huruf besar tetap kena
+	_, err := useCase.LoginUseCase("Budi@Example.com", "Rahasia123!", "10.0.0.2", "go-test")
+	if !errors.Is(err, apperror.ErrTooManyRequests) {
+		t.Fatalf("locked login error = %v, want too many requests", err)
+	}
+	if last := attempts.outcomes[len(attempts.outcomes)-1]; last != model.LoginOutcomeLocked {
+		t.Errorf("login history outcome = %s, want %s", last, model.LoginOutcomeLocked)
+	}
+
+	// Akun lain dari IP yang sama belum terkunci
+	result, err := useCase.LoginUseCase("siti@example.com", "Rahasia123!", "10.0.0.1", "go-test")
+	if err != nil || result.Tokens.AccessToken == "" {
+		t.Errorf("other account login = %+v, %v, want tokens", result, err)
+	}
+}
+
+func TestLoginSuccessResetsAccountFailuresOnly(t *testing.T) {
+	attempts := newMemoryLoginAttemptRepository()
+	useCase := newLoginTestUseCase(t, attempts)
+
+	for i := 0; i < 2; i++ {
+		useCase.LoginUseCase("budi@example.com", "salah", "10.0.0.1", "go-test")
+	}
+
+	_, err := useCase.LoginUseCase("budi@example.com", "Rahasia123!", "10.0.0.1", "go-test")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	if got := attempts.failures["account:budi@example.com"]; got != 0 {
+		t.Errorf("account failures after success = %d, want 0", got)
+	}
+	// Login dengan akun sendiri tidak membuka hitungan per IP
+	if got := attempts.failures["ip:10.0.0.1"]; got != 2 {
+		t.Errorf("ip failures after success = %d, want 2", got)
+	}
+}
+
+func TestLoginLocksIPAcrossAccounts(t *testing.T) {
+	attempts := newMemoryLoginAttemptRepository()
+	useCase := newLoginTestUseCase(t, attempts)
+
+	// Menebak banyak email dari satu IP, tidak ada akun yang mencapai batas per akun
+	for i := 0; i < 5; i++ {
+		useCase.LoginUseCase(fmt.Sprintf("user%d@example.com", i), "salah", "10.0.0.1", "go-test")
+	}
+
+	_, err := useCase.LoginUseCase("budi@example.com", "Rahasia123!", "10.0.0.1", "go-test")
+	if !errors.Is(err, apperror.ErrTooManyRequests) {
+		t.Fatalf("login from locked ip error = %v, want too many requests", err)
+	}
+
+	_, err = useCase.LoginUseCase("budi@example.com", "Rahasia123!", "10.0.0.2", "go-test")
+	if err != nil {
+		t.Errorf("login from another ip: %v", err)
+	}
+}
@@ -3,7 +3,11 @@ package usecase
 import (
 	"edu-learn/model"
 	"edu-learn/repository"
-	"fmt"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/policy"
+	"errors"
+	"strings"
 )
 
 type courseUseCase struct {
@@ -12,72 +16,134 @@ type courseUseCase struct {
 }
 
 type CourseUseCase interface {
-	CreateCourse(course *model.Course) (model.Course, error)
-	GetAllCourse() ([]model.Course, error)
+	CreateCourse(course *model.Course, actor model.User) (model.Course, error)
+	GetAllCourse(filter modelutils.CourseFilter, page modelutils.PageQuery) ([]model.Course, int64, error)
 	GetCourseById(id int) (model.Course, error)
-	UpdateCourse(id int, course *model.Course) (model.Course, error)
-	DeleteCourse(id int) error
-	GetCoursesByUserID(userID int) ([]model.Course, error)
+	UpdateCourse(id int, course *model.Course, actor model.User) (model.Course, error)
+	DeleteCourse(id int, actor model.User) error
+	GetCoursesByUserID(userID int, page modelutils.PageQuery, actor model.User) ([]model.Course, int64, error)
+	SearchCourses(text string, page modelutils.PageQuery) ([]modelutils.CourseSearchResult, int64, error)
 }
 
-func (c *courseUseCase) CreateCourse(course *model.Course) (model.Course, error) {
-	idInstructor := course.InstructorID
+func (c *courseUseCase) CreateCourse(course *model.Course, actor model.User) (model.Course, error) {
+	// Instruktur selalu membuat kursus atas namanya sendiri, hanya admin yang boleh memilih instruktur
+	if policy.ScopeOf(actor.Role, policy.CourseCreate) != policy.ScopeAny {
+		if course.InstructorID != 0 {
+			return model.Course{}, apperror.Forbidden("instructor_id is only allowed for admin")
+		}
+		course.InstructorID = actor.ID
+	}
+
+	err := policy.Authorize(actor, policy.CourseCreate, course.InstructorID == actor.ID)
+	if err != nil {
+		return model.Course{}, err
+	}
 
-	_, err := c.repoUser.GetUserById(idInstructor)
+	err = c.checkInstructor(course.InstructorID)
 	if err != nil {
-		return model.Course{}, fmt.Errorf("instructor not found")
+		return model.Course{}, err
 	}
 
 	return c.repo.CreateCourse(course)
 }
 
-func (c *courseUseCase) GetAllCourse() ([]model.Course, error) {
-	return c.repo.GetAllCourse()
+func (c *courseUseCase) GetAllCourse(filter modelutils.CourseFilter, page modelutils.PageQuery) ([]model.Course, int64, error) {
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, 0, apperror.BadRequest("min_price must not be greater than max_price")
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return nil, 0, apperror.BadRequest("created_from must not be after created_to")
+	}
+
+	return c.repo.GetAllCourse(filter, page)
 }
 
 func (c *courseUseCase) GetCourseById(id int) (model.Course, error) {
 	if id <= 0 {
-		return model.Course{}, fmt.Errorf("invalid course id")
+		return model.Course{}, apperror.BadRequest("invalid course id")
 	}
 
 	return c.repo.GetCourseById(id)
 }
 
-func (c *courseUseCase) UpdateCourse(id int, course *model.Course) (model.Course, error) {
-	_, err := c.repo.GetCourseById(id)
+func (c *courseUseCase) UpdateCourse(id int, course *model.Course, actor model.User) (model.Course, error) {
+	existingCourse, err := c.repo.GetCourseById(id)
 	if err != nil {
-		return model.Course{}, fmt.Errorf("course not found")
+		return model.Course{}, err
 	}
 
-	idInstructor := course.InstructorID
-	_, err = c.repoUser.GetUserById(idInstructor)
+	err = policy.Authorize(actor, policy.CourseUpdate, existingCourse.InstructorID == actor.ID)
 	if err != nil {
-		return model.Course{}, fmt.Errorf("instructor not found")
+		return model.Course{}, err
+	}
+
+	// Kursus tidak bisa dipindahkan ke instruktur lain kecuali oleh admin
+	if course.InstructorID != 0 && policy.ScopeOf(actor.Role, policy.CourseUpdate) != policy.ScopeAny {
+		return model.Course{}, apperror.Forbidden("instructor_id is only allowed for admin")
+	}
+	if course.InstructorID == 0 {
+		course.InstructorID = existingCourse.InstructorID
+	}
+
+	err = c.checkInstructor(course.InstructorID)
+	if err != nil {
+		return model.Course{}, err
 	}
 
 	return c.repo.UpdateCourse(id, course)
 }
 
-func (c *courseUseCase) DeleteCourse(id int) error {
-	_, err := c.repo.GetCourseById(id)
+func (c *courseUseCase) DeleteCourse(id int, actor model.User) error {
+	course, err := c.repo.GetCourseById(id)
+	if err != nil {
+		return err
+	}
+
+	err = policy.Authorize(actor, policy.CourseDelete, course.InstructorID == actor.ID)
 	if err != nil {
-		return fmt.Errorf("course not found")
+		return err
 	}
 
 	return c.repo.DeleteCourse(id)
 }
 
-func (c *courseUseCase) GetCoursesByUserID(userID int) ([]model.Course, error) {
+func (c *courseUseCase) GetCoursesByUserID(userID int, page modelutils.PageQuery, actor model.User) ([]model.Course, int64, error) {
+	err := policy.Authorize(actor, policy.UserRead, userID == actor.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	user, err := c.repoUser.GetUserById(userID)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	if user.Role != "student" {
-		return []model.Course{}, fmt.Errorf("forbidden: only students can access their courses")
+		return nil, 0, apperror.Forbidden("forbidden: only students can access their courses")
+	}
+
+	return c.repo.GetCoursesByUserID(userID, page)
+}
+
+func (c *courseUseCase) SearchCourses(text string, page modelutils.PageQuery) ([]modelutils.CourseSearchResult, int64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, 0, apperror.BadRequest("search query is required")
+	}
+
+	return c.repo.SearchCourses(text, page)
+}
+
+func (c *courseUseCase) checkInstructor(id int) error {
+	instructor, err := c.repoUser.GetUserById(id)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return err
+	}
+	if err != nil || instructor.Role != "instructor" {
+		return apperror.NotFound("instructor not found")
 	}
 
-	return c.repo.GetCoursesByUserID(userID)
+	return nil
 }
 
 func NewCourseUsecase(repo repository.CourseRepository, repoUser repository.UserRepository) CourseUseCase {
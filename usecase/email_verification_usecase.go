@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"edu-learn/config"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/service"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type emailVerificationUseCase struct {
+	repoUser    repository.UserRepository
+	mailUseCase MailUseCase
+	cfg         config.Config
+}
+
+type EmailVerificationUseCase interface {
+	SendVerificationEmail(user model.User) error
+	VerifyEmail(token string) error
+	ResendVerificationEmail(email string) error
+}
+
+func (e *emailVerificationUseCase) SendVerificationEmail(user model.User) error {
+	expiresAt := time.Now().Add(e.cfg.VerifyTokenLifeTime)
+	token := service.SignEmailVerificationToken(e.cfg.EmailVerificationSecret, user.ID, user.Email, expiresAt)
+
+	link := fmt.Sprintf("%s/api/email/verify?token=%s", e.cfg.AppBaseURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Halo %s,\n\nTerima kasih telah mendaftar di EduLearn.\n"+
+		"Buka link berikut dalam %d jam untuk memverifikasi email Anda:\n\n%s\n\nAbaikan email ini jika Anda tidak merasa mendaftar.",
+		user.Name, int(e.cfg.VerifyTokenLifeTime.Hours()), link)
+
+	return e.mailUseCase.QueueEmail(user.Email, "Verifikasi email EduLearn", body)
+}
+
+func (e *emailVerificationUseCase) VerifyEmail(token string) error {
+	userID, expiresAt, err := service.ParseEmailVerificationToken(token)
+	if err != nil {
+		return apperror.BadRequest("invalid or expired verification token")
+	}
+
+	user, err := e.repoUser.GetUserById(userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return apperror.BadRequest("invalid or expired verification token")
+		}
+		return err
+	}
+
+	if !service.VerifyEmailVerificationToken(e.cfg.EmailVerificationSecret, token, user.Email) || time.Now().After(expiresAt) {
+		return apperror.BadRequest("invalid or expired verification token")
+	}
+
+	// Link yang diklik dua kali tetap dianggap sukses
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return e.repoUser.MarkEmailVerified(user.ID, time.Now())
+}
+
+// ResendVerificationEmail => Selalu sukses walaupun email tidak terdaftar atau sudah diverifikasi
+func (e *emailVerificationUseCase) ResendVerificationEmail(email string) error {
+	user, err := e.repoUser.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return e.SendVerificationEmail(user)
+}
+
+func NewEmailVerificationUseCase(repoUser repository.UserRepository, mailUseCase MailUseCase, cfg config.Config) EmailVerificationUseCase {
+	return &emailVerificationUseCase{repoUser: repoUser, mailUseCase: mailUseCase, cfg: cfg}
+}
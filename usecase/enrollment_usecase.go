@@ -1,36 +1,55 @@
 package usecase
 
 import (
+	"edu-learn/model"
 	"edu-learn/repository"
-	"fmt"
+	"edu-learn/utils/apperror"
 )
 
 type enrollmentUseCase struct {
-	repo repository.EnrollmentRepository
+	repo        repository.EnrollmentRepository
+	repoCourse  repository.CourseRepository
+	repoPayment repository.PaymentRepository
 }
 
 type EnrollmentUseCase interface {
 	IsEnrolled(userID, courseID int) (bool, error)
-	EnrollCourse(userID, courseID int) error
+	EnrollCourse(userID, courseID int) (model.Enrollment, error)
 }
 
 func (e *enrollmentUseCase) IsEnrolled(userID, courseID int) (bool, error) {
-    return e.repo.IsEnrolled(userID, courseID)
+	return e.repo.IsEnrolled(userID, courseID)
 }
 
-func (e *enrollmentUseCase) EnrollCourse(userID, courseID int) error {
+func (e *enrollmentUseCase) EnrollCourse(userID, courseID int) (model.Enrollment, error) {
+	course, err := e.repoCourse.GetCourseById(courseID)
+	if err != nil {
+		return model.Enrollment{}, err
+	}
+
 	alreadyEnrolled, err := e.IsEnrolled(userID, courseID)
 	if err != nil {
-		return err
+		return model.Enrollment{}, err
 	}
 
 	if alreadyEnrolled {
-		return fmt.Errorf("user already enrolled in course")
+		return model.Enrollment{}, apperror.Conflict("user already enrolled in course")
+	}
+
+	// Kursus berbayar hanya bisa diikuti setelah pembayaran selesai
+	if course.Price > 0 {
+		paid, err := e.repoPayment.HasCompletedPayment(userID, courseID)
+		if err != nil {
+			return model.Enrollment{}, err
+		}
+		if !paid {
+			return model.Enrollment{}, apperror.PaymentRequired("payment required")
+		}
 	}
 
 	return e.repo.CreateEnrollment(userID, courseID)
 }
 
-func NewEnrollmentUseCase(repo repository.EnrollmentRepository) EnrollmentUseCase {
-	return &enrollmentUseCase{repo: repo}
+func NewEnrollmentUseCase(repo repository.EnrollmentRepository, repoCourse repository.CourseRepository, repoPayment repository.PaymentRepository) EnrollmentUseCase {
+	return &enrollmentUseCase{repo: repo, repoCourse: repoCourse, repoPayment: repoPayment}
 }
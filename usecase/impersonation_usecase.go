@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/service"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type impersonationUseCase struct {
+	repo       repository.ImpersonationRepository
+	repoUser   repository.UserRepository
+	jwtService service.JwtService
+}
+
+type ImpersonationUseCase interface {
+	Impersonate(userID int, reason string, actor model.User, ipAddress string, userAgent string) (modelutils.ImpersonationToken, error)
+	GetAuditLogs(impersonatorID int, userID int, page modelutils.PageQuery, actor model.User) ([]model.ImpersonationAuditLog, int64, error)
+}
+
+// Impersonate => Admin mendapat token user lain, dimulainya impersonation dicatat bersama alasannya
+func (i *impersonationUseCase) Impersonate(userID int, reason string, actor model.User, ipAddress string, userAgent string) (modelutils.ImpersonationToken, error) {
+	err := policy.Authorize(actor, policy.UserImpersonate, false)
+	if err != nil {
+		return modelutils.ImpersonationToken{}, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return modelutils.ImpersonationToken{}, apperror.BadRequest("reason is required")
+	}
+
+	if userID == actor.ID {
+		return modelutils.ImpersonationToken{}, apperror.BadRequest("cannot impersonate yourself")
+	}
+
+	user, err := i.repoUser.GetUserById(userID)
+	if err != nil {
+		return modelutils.ImpersonationToken{}, err
+	}
+
+	// Admin lain dan service account tidak bisa ditiru supaya impersonation tidak menambah hak akses
+	if user.Role == "admin" || user.AccountType == model.AccountTypeService {
+		return modelutils.ImpersonationToken{}, apperror.Forbidden("user cannot be impersonated")
+	}
+
+	token, expiresAt, err := i.jwtService.CreateImpersonationToken(user, actor)
+	if err != nil {
+		return modelutils.ImpersonationToken{}, err
+	}
+
+	claim, err := i.jwtService.VerifyToken(token)
+	if err != nil {
+		return modelutils.ImpersonationToken{}, err
+	}
+
+	err = i.repo.CreateAuditLog(&model.ImpersonationAuditLog{
+		TokenID:        claim.ID,
+		ImpersonatorID: &actor.ID,
+		UserID:         &user.ID,
+		Method:         http.MethodPost,
+		Path:           fmt.Sprintf("/api/users/%d/impersonate", user.ID),
+		StatusCode:     http.StatusCreated,
+		Reason:         reason,
+		IPAddress:      ipAddress,
+		UserAgent:      userAgent,
+	})
+	if err != nil {
+		return modelutils.ImpersonationToken{}, err
+	}
+
+	return modelutils.ImpersonationToken{AccessToken: token, ExpiresAt: expiresAt}, nil
+}
+
+func (i *impersonationUseCase) GetAuditLogs(impersonatorID int, userID int, page modelutils.PageQuery, actor model.User) ([]model.ImpersonationAuditLog, int64, error) {
+	err := policy.Authorize(actor, policy.AuditLogRead, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return i.repo.GetAuditLogs(impersonatorID, userID, page)
+}
+
+func NewImpersonationUseCase(repo repository.ImpersonationRepository, repoUser repository.UserRepository, jwtService service.JwtService) ImpersonationUseCase {
+	return &impersonationUseCase{repo: repo, repoUser: repoUser, jwtService: jwtService}
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/service"
+	"fmt"
+	"log"
+	"strings"
+)
+
+type instructorApplicationUseCase struct {
+	repo              repository.InstructorApplicationRepository
+	repoUser          repository.UserRepository
+	mailUseCase       MailUseCase
+	revocationService service.TokenRevocationService
+}
+
+type InstructorApplicationUseCase interface {
+	Apply(application *model.InstructorApplication, actor model.User) (model.InstructorApplication, error)
+	GetApplications(status string, page modelutils.PageQuery, actor model.User) ([]model.InstructorApplication, int64, error)
+	GetApplicationById(id int, actor model.User) (model.InstructorApplication, error)
+	ReviewApplication(id int, approve bool, notes string, actor model.User) (model.InstructorApplication, error)
+}
+
+func (i *instructorApplicationUseCase) Apply(application *model.InstructorApplication, actor model.User) (model.InstructorApplication, error) {
+	err := policy.Authorize(actor, policy.ApplicationCreate, true)
+	if err != nil {
+		return model.InstructorApplication{}, err
+	}
+
+	if strings.TrimSpace(application.Bio) == "" || strings.TrimSpace(application.Credentials) == "" {
+		return model.InstructorApplication{}, apperror.BadRequest("bio and credentials are required")
+	}
+
+	application.UserID = actor.ID
+	application.ReviewerID = nil
+	application.ReviewNotes = ""
+	application.ReviewedAt = nil
+
+	return i.repo.CreateApplication(application)
+}
+
+// GetApplications => Admin melihat semua pengajuan, user lain hanya pengajuannya sendiri
+func (i *instructorApplicationUseCase) GetApplications(status string, page modelutils.PageQuery, actor model.User) ([]model.InstructorApplication, int64, error) {
+	if status != "" && status != model.ApplicationStatusPending && status != model.ApplicationStatusApproved && status != model.ApplicationStatusRejected {
+		return nil, 0, apperror.BadRequest("invalid application status")
+	}
+
+	if policy.ScopeOf(actor.Role, policy.ApplicationRead) == policy.ScopeAny {
+		return i.repo.GetApplications(status, page)
+	}
+
+	err := policy.Authorize(actor, policy.ApplicationRead, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return i.repo.GetApplicationsByUserID(actor.ID, page)
+}
+
+func (i *instructorApplicationUseCase) GetApplicationById(id int, actor model.User) (model.InstructorApplication, error) {
+	if id <= 0 {
+		return model.InstructorApplication{}, apperror.BadRequest("invalid application id")
+	}
+
+	application, err := i.repo.GetApplicationById(id)
+	if err != nil {
+		return model.InstructorApplication{}, err
+	}
+
+	err = policy.Authorize(actor, policy.ApplicationRead, application.UserID == actor.ID)
+	if err != nil {
+		return model.InstructorApplication{}, err
+	}
+
+	return application, nil
+}
+
+// ReviewApplication => Disetujui berarti role naik jadi instructor dan user harus login ulang
+func (i *instructorApplicationUseCase) ReviewApplication(id int, approve bool, notes string, actor model.User) (model.InstructorApplication, error) {
+	err := policy.Authorize(actor, policy.ApplicationReview, false)
+	if err != nil {
+		return model.InstructorApplication{}, err
+	}
+
+	status := model.ApplicationStatusRejected
+	if approve {
+		status = model.ApplicationStatusApproved
+	}
+
+	application, err := i.repo.ReviewApplication(id, status, actor.ID, notes)
+	if err != nil {
+		return model.InstructorApplication{}, err
+	}
+
+	// Review sudah tersimpan, langkah setelah ini best-effort dan hanya dicatat di log jika gagal.
+	// Token lama berisi role student, jadi jika pencabutan gagal user hanya tertinggal hak aksesnya sampai token habis
+	if approve {
+		err = i.revocationService.RevokeUserTokens(application.UserID)
+		if err != nil {
+			log.Printf("failed to revoke tokens of user %d after application %d approved: %v", application.UserID, application.ID, err)
+		}
+	}
+
+	applicant, err := i.repoUser.GetUserById(application.UserID)
+	if err != nil {
+		log.Printf("failed to get applicant of application %d: %v", application.ID, err)
+		return application, nil
+	}
+
+	err = i.mailUseCase.QueueEmail(applicant.Email, "Pengajuan instruktur EduLearn", applicationEmailBody(applicant, application))
+	if err != nil {
+		log.Printf("failed to queue email for application %d: %v", application.ID, err)
+	}
+
+	return application, nil
+}
+
+func applicationEmailBody(applicant model.User, application model.InstructorApplication) string {
+	var body string
+	if application.Status == model.ApplicationStatusApproved {
+		body = fmt.Sprintf("Halo %s,\n\nSelamat, pengajuan Anda sebagai instruktur EduLearn telah disetujui.\n"+
+			"Silakan login ulang untuk mulai membuat kursus.", applicant.Name)
+	} else {
+		body = fmt.Sprintf("Halo %s,\n\nMohon maaf, pengajuan Anda sebagai instruktur EduLearn belum dapat kami setujui.", applicant.Name)
+	}
+
+	if application.ReviewNotes != "" {
+		body += "\n\nCatatan dari admin:\n" + application.ReviewNotes
+	}
+
+	return body
+}
+
+func NewInstructorApplicationUseCase(repo repository.InstructorApplicationRepository, repoUser repository.UserRepository, mailUseCase MailUseCase, revocationService service.TokenRevocationService) InstructorApplicationUseCase {
+	return &instructorApplicationUseCase{repo: repo, repoUser: repoUser, mailUseCase: mailUseCase, revocationService: revocationService}
+}
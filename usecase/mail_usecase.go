@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"edu-learn/model"
+	"edu-learn/repository"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/service"
+	"errors"
+	"time"
+)
+
+const (
+	mailBatchSize   = 20
+	mailMaxAttempts = 5
+	mailSendLease   = 10 * time.Minute // email sending yang lebih lama dari ini dianggap worker-nya mati
+)
+
+type mailUseCase struct {
+	repo   repository.EmailOutboxRepository
+	sender service.MailSender
+	from   string
+}
+
+type MailUseCase interface {
+	QueueEmail(to string, subject string, body string) error
+	DispatchPendingEmails() (int, error)
+}
+
+func (m *mailUseCase) QueueEmail(to string, subject string, body string) error {
+	_, err := m.repo.EnqueueEmail(&model.EmailOutbox{
+		Recipient: to,
+		Subject:   subject,
+		Body:      body,
+	})
+	return err
+}
+
+// DispatchPendingEmails => Dipanggil berkala oleh worker untuk mengirim isi outbox.
+// Email dikirim di luar transaksi database, jadi commit yang gagal tidak membuat email terkirim dua kali
+func (m *mailUseCase) DispatchPendingEmails() (int, error) {
+	emails, err := m.repo.ClaimPendingEmails(mailBatchSize, mailSendLease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var markErrs []error
+	for _, email := range emails {
+		sendErr := m.sender.Send(modelutils.MailMessage{
+			From:    m.from,
+			To:      email.Recipient,
+			Subject: email.Subject,
+			Body:    email.Body,
+		})
+		if sendErr != nil {
+			err = m.repo.MarkEmailFailed(email.ID, sendErr, email.Attempts >= mailMaxAttempts)
+		} else {
+			sent++
+			err = m.repo.MarkEmailSent(email.ID)
+		}
+
+		// Email lain tetap dikirim, yang gagal ditandai akan diambil lagi setelah lease habis
+		if err != nil {
+			markErrs = append(markErrs, err)
+		}
+	}
+
+	return sent, errors.Join(markErrs...)
+}
+
+func NewMailUseCase(repo repository.EmailOutboxRepository, sender service.MailSender, from string) MailUseCase {
+	return &mailUseCase{repo: repo, sender: sender, from: from}
+}
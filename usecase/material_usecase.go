@@ -3,26 +3,29 @@ package usecase
 import (
 	"edu-learn/model"
 	"edu-learn/repository"
-	"fmt"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/policy"
 )
 
 type materialUseCase struct {
-	repo       repository.MaterialRepository
-	repoCourse repository.CourseRepository
+	repo           repository.MaterialRepository
+	repoCourse     repository.CourseRepository
+	repoEnrollment repository.EnrollmentRepository
 }
 
 type MaterialUseCase interface {
-	CreateMaterial(idCourse int, material *model.Material) (model.Material, error)
-	GetAllMaterial(idCourse int) ([]model.Material, error)
-	GetMaterialById(idCourse int, idMaterial int) (model.Material, error)
-	UpdateMaterial(idCourse int, idMaterial int, material *model.Material) (model.Material, error)
-	DeleteMaterial(idCourse int, idMaterial int) error
+	CreateMaterial(idCourse int, material *model.Material, actor model.User) (model.Material, error)
+	GetAllMaterial(idCourse int, page modelutils.PageQuery, actor model.User) ([]model.Material, int64, error)
+	GetMaterialById(idCourse int, idMaterial int, actor model.User) (model.Material, error)
+	UpdateMaterial(idCourse int, idMaterial int, material *model.Material, actor model.User) (model.Material, error)
+	DeleteMaterial(idCourse int, idMaterial int, actor model.User) error
 }
 
-func (m *materialUseCase) CreateMaterial(idCourse int, material *model.Material) (model.Material, error) {
-	_, err := m.repoCourse.GetCourseById(idCourse)
+func (m *materialUseCase) CreateMaterial(idCourse int, material *model.Material, actor model.User) (model.Material, error) {
+	err := m.authorizeCourse(idCourse, actor, policy.MaterialCreate)
 	if err != nil {
-		return model.Material{}, fmt.Errorf("course not found")
+		return model.Material{}, err
 	}
 
 	material.CourseID = idCourse
@@ -30,50 +33,78 @@ func (m *materialUseCase) CreateMaterial(idCourse int, material *model.Material)
 	return m.repo.CreateMaterial(material)
 }
 
-func (m *materialUseCase) GetAllMaterial(idCourse int) ([]model.Material, error) {
-	_, err := m.repoCourse.GetCourseById(idCourse)
+func (m *materialUseCase) GetAllMaterial(idCourse int, page modelutils.PageQuery, actor model.User) ([]model.Material, int64, error) {
+	err := m.authorizeCourse(idCourse, actor, policy.MaterialRead)
 	if err != nil {
-		return []model.Material{}, fmt.Errorf("course not found")
+		return nil, 0, err
 	}
 
-	return m.repo.GetAllMaterial(idCourse)
+	return m.repo.GetAllMaterial(idCourse, page)
 }
 
-func (m *materialUseCase) GetMaterialById(idCourse int, idMaterial int) (model.Material, error) {
+func (m *materialUseCase) GetMaterialById(idCourse int, idMaterial int, actor model.User) (model.Material, error) {
 	if idCourse <= 0 {
-		return model.Material{}, fmt.Errorf("invalid course id")
+		return model.Material{}, apperror.BadRequest("invalid course id")
 	}
 
 	if idMaterial <= 0 {
-		return model.Material{}, fmt.Errorf("invalid material id")
+		return model.Material{}, apperror.BadRequest("invalid material id")
+	}
+
+	err := m.authorizeCourse(idCourse, actor, policy.MaterialRead)
+	if err != nil {
+		return model.Material{}, err
 	}
 
 	return m.repo.GetMaterialById(idCourse, idMaterial)
 }
 
-func (m *materialUseCase) UpdateMaterial(idCourse int, idMaterial int, material *model.Material) (model.Material, error) {
-	_, err := m.repoCourse.GetCourseById(idCourse)
+func (m *materialUseCase) UpdateMaterial(idCourse int, idMaterial int, material *model.Material, actor model.User) (model.Material, error) {
+	err := m.authorizeCourse(idCourse, actor, policy.MaterialUpdate)
 	if err != nil {
-		return model.Material{}, fmt.Errorf("course not found")
+		return model.Material{}, err
 	}
 
-	_, err = m.GetMaterialById(idCourse, idMaterial)
+	_, err = m.repo.GetMaterialById(idCourse, idMaterial)
 	if err != nil {
-		return model.Material{}, fmt.Errorf("material not found")
+		return model.Material{}, err
 	}
 
 	return m.repo.UpdateMaterial(idCourse, idMaterial, material)
 }
 
-func (m *materialUseCase) DeleteMaterial(idCourse int, idMaterial int) error {
-	_, err := m.GetMaterialById(idCourse, idMaterial)
+func (m *materialUseCase) DeleteMaterial(idCourse int, idMaterial int, actor model.User) error {
+	err := m.authorizeCourse(idCourse, actor, policy.MaterialDelete)
 	if err != nil {
-		return fmt.Errorf("material not found")
+		return err
+	}
+
+	_, err = m.repo.GetMaterialById(idCourse, idMaterial)
+	if err != nil {
+		return err
 	}
 
 	return m.repo.DeleteMaterial(idCourse, idMaterial)
 }
 
-func NewMaterialUseCase(repo repository.MaterialRepository, repoCourse repository.CourseRepository) MaterialUseCase {
-	return &materialUseCase{repo: repo, repoCourse: repoCourse}
+// authorizeCourse => Materi milik instruktur kursus, siswa hanya bisa membaca materi kursus yang diikuti
+func (m *materialUseCase) authorizeCourse(idCourse int, actor model.User, permission policy.Permission) error {
+	course, err := m.repoCourse.GetCourseById(idCourse)
+	if err != nil {
+		return err
+	}
+
+	isOwner := course.InstructorID == actor.ID
+	if !isOwner && permission == policy.MaterialRead && policy.ScopeOf(actor.Role, permission) == policy.ScopeOwn {
+		isOwner, err = m.repoEnrollment.IsEnrolled(actor.ID, idCourse)
+		if err != nil {
+			return err
+		}
+	}
+
+	return policy.Authorize(actor, permission, isOwner)
+}
+
+func NewMaterialUseCase(repo repository.MaterialRepository, repoCourse repository.CourseRepository, repoEnrollment repository.EnrollmentRepository) MaterialUseCase {
+	return &materialUseCase{repo: repo, repoCourse: repoCourse, repoEnrollment: repoEnrollment}
 }
@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"edu-learn/config"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/service"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const mfaRecoveryCodeCount = 10
+
+type mfaUseCase struct {
+	repo             repository.MFARepository
+	repoUser         repository.UserRepository
+	repoLoginAttempt repository.LoginAttemptRepository
+	cfg              config.Config
+}
+
+type MFAUseCase interface {
+	Enroll(userID int) (modelutils.MFAEnrollment, error)
+	ConfirmEnrollment(userID int, code string) ([]string, error)
+	VerifyCode(userID int, code string) error
+	RegenerateRecoveryCodes(userID int, code string) ([]string, error)
+	Disable(user model.User, code string) error
+	IsEnabled(userID int) (bool, error)
+	IsRequired(role string) (bool, error)
+	GetRolePolicies(page modelutils.PageQuery) ([]model.MFARolePolicy, int64, error)
+	SetRolePolicy(role string, required bool) (model.MFARolePolicy, error)
+}
+
+// Enroll => Buat secret baru, MFA belum aktif sampai dikonfirmasi dengan kode dari aplikasi
+func (m *mfaUseCase) Enroll(userID int) (modelutils.MFAEnrollment, error) {
+	enabled, err := m.IsEnabled(userID)
+	if err != nil {
+		return modelutils.MFAEnrollment{}, err
+	}
+	if enabled {
+		return modelutils.MFAEnrollment{}, apperror.Conflict("mfa already enabled")
+	}
+
+	user, err := m.repoUser.GetUserById(userID)
+	if err != nil {
+		return modelutils.MFAEnrollment{}, err
+	}
+
+	secret, err := service.GenerateTOTPSecret()
+	if err != nil {
+		return modelutils.MFAEnrollment{}, err
+	}
+
+	encrypted, err := service.EncryptSecret(m.cfg.MFAEncryptionKey, secret)
+	if err != nil {
+		return modelutils.MFAEnrollment{}, err
+	}
+
+	_, err = m.repo.SaveMFASecret(&model.UserMFA{UserID: userID, Secret: encrypted})
+	if err != nil {
+		return modelutils.MFAEnrollment{}, err
+	}
+
+	return modelutils.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: service.TOTPProvisioningURI(m.issuer(), user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment => Kode pertama dari aplikasi mengaktifkan MFA, recovery code hanya ditampilkan sekali.
+// Percobaan gagal dihitung bersama VerifyCode
+func (m *mfaUseCase) ConfirmEnrollment(userID int, code string) ([]string, error) {
+	attemptKey := mfaAttemptKey(userID)
+
+	err := m.checkLocked(attemptKey)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := m.repo.GetMFAByUserID(userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, apperror.BadRequest("mfa enrollment not started")
+		}
+		return nil, err
+	}
+	if mfa.ConfirmedAt != nil {
+		return nil, apperror.Conflict("mfa already enabled")
+	}
+
+	step, ok, err := m.validateTOTP(mfa, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, m.registerFailure(attemptKey)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.repo.ConfirmMFA(userID, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.repoLoginAttempt.ResetFailures(attemptKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// VerifyCode => Terima kode TOTP atau recovery code, gagal berulang kali akan dikunci sementara
+func (m *mfaUseCase) VerifyCode(userID int, code string) error {
+	attemptKey := mfaAttemptKey(userID)
+
+	err := m.checkLocked(attemptKey)
+	if err != nil {
+		return err
+	}
+
+	mfa, err := m.repo.GetMFAByUserID(userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return apperror.BadRequest("mfa not enabled")
+		}
+		return err
+	}
+	if mfa.ConfirmedAt == nil {
+		return apperror.BadRequest("mfa not enabled")
+	}
+
+	valid := false
+	code = strings.TrimSpace(code)
+
+	step, ok, err := m.validateTOTP(mfa, code)
+	if err != nil {
+		return err
+	}
+	if ok {
+		// Kode yang sama tidak boleh dipakai dua kali dalam periode yang sama
+		valid, err = m.repo.UseTOTPStep(userID, step)
+	} else {
+		valid, err = m.repo.UseRecoveryCode(userID, hashRecoveryCode(code))
+	}
+	if err != nil {
+		return err
+	}
+
+	if !valid {
+		return m.registerFailure(attemptKey)
+	}
+
+	return m.repoLoginAttempt.ResetFailures(attemptKey)
+}
+
+func (m *mfaUseCase) RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	err := m.VerifyCode(userID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.repo.ReplaceRecoveryCodes(userID, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable => Tidak bisa dimatikan jika role user wajib MFA
+func (m *mfaUseCase) Disable(user model.User, code string) error {
+	required, err := m.IsRequired(user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return apperror.Forbidden("mfa is required for your role")
+	}
+
+	err = m.VerifyCode(user.ID, code)
+	if err != nil {
+		return err
+	}
+
+	return m.repo.DeleteMFA(user.ID)
+}
+
+func (m *mfaUseCase) IsEnabled(userID int) (bool, error) {
+	mfa, err := m.repo.GetMFAByUserID(userID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return mfa.ConfirmedAt != nil, nil
+}
+
+func (m *mfaUseCase) IsRequired(role string) (bool, error) {
+	return m.repo.IsMFARequired(role)
+}
+
+func (m *mfaUseCase) GetRolePolicies(page modelutils.PageQuery) ([]model.MFARolePolicy, int64, error) {
+	return m.repo.GetRolePolicies(page)
+}
+
+func (m *mfaUseCase) SetRolePolicy(role string, required bool) (model.MFARolePolicy, error) {
+	if role != "student" && role != "instructor" && role != "admin" {
+		return model.MFARolePolicy{}, apperror.BadRequest("invalid role")
+	}
+
+	return m.repo.SetRolePolicy(&model.MFARolePolicy{Role: role, Required: required})
+}
+
+func (m *mfaUseCase) checkLocked(attemptKey string) error {
+	lockedUntil, err := m.repoLoginAttempt.GetLockedUntil(attemptKey)
+	if err != nil {
+		return err
+	}
+	if !lockedUntil.IsZero() {
+		return apperror.TooManyRequests("too many mfa attempts")
+	}
+	return nil
+}
+
+// registerFailure => Catat kode yang salah, selalu mengembalikan error untuk request ini
+func (m *mfaUseCase) registerFailure(attemptKey string) error {
+	_, err := m.repoLoginAttempt.RegisterFailure(attemptKey, m.cfg.MaxLoginAttempts, m.cfg.LoginLockoutBase, m.cfg.LoginLockoutMax)
+	if err != nil {
+		return err
+	}
+	return apperror.Unauthorized("invalid mfa code")
+}
+
+func (m *mfaUseCase) validateTOTP(mfa model.UserMFA, code string) (int64, bool, error) {
+	secret, err := service.DecryptSecret(m.cfg.MFAEncryptionKey, mfa.Secret)
+	if err != nil {
+		return 0, false, err
+	}
+
+	step, ok := service.ValidateTOTP(secret, code, time.Now())
+	return step, ok, nil
+}
+
+// mfaAttemptKey => Satu counter untuk konfirmasi enrollment dan verifikasi kode
+func mfaAttemptKey(userID int) string {
+	return "mfa:" + strconv.Itoa(userID)
+}
+
+func (m *mfaUseCase) issuer() string {
+	if m.cfg.ApplicationName == "" {
+		return "EduLearn"
+	}
+	return m.cfg.ApplicationName
+}
+
+// newRecoveryCodes => Kode berformat xxxx-xxxx-xxxx, yang disimpan hanya hash-nya
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	hashes := make([]string, 0, mfaRecoveryCodeCount)
+
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		b := make([]byte, 6)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		raw := hex.EncodeToString(b)
+		code := raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode => Tanda hubung dan huruf besar diabaikan saat user mengetik ulang
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	return hashSecureToken(normalized)
+}
+
+func NewMFAUseCase(repo repository.MFARepository, repoUser repository.UserRepository, repoLoginAttempt repository.LoginAttemptRepository, cfg config.Config) MFAUseCase {
+	return &mfaUseCase{repo: repo, repoUser: repoUser, repoLoginAttempt: repoLoginAttempt, cfg: cfg}
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"edu-learn/config"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/service"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeMFARepository struct {
+	repository.MFARepository
+	mfa model.UserMFA
+}
+
+func (f *fakeMFARepository) GetMFAByUserID(userID int) (model.UserMFA, error) {
+	return f.mfa, nil
+}
+
+// memoryLoginAttemptRepository => Meniru repository: key dikunci setelah gagal sebanyak threshold
+type memoryLoginAttemptRepository struct {
+	repository.LoginAttemptRepository
+	failures    map[string]int
+	lockedUntil map[string]time.Time
+	outcomes    []string
+}
+
+func newMemoryLoginAttemptRepository() *memoryLoginAttemptRepository {
+	return &memoryLoginAttemptRepository{failures: map[string]int{}, lockedUntil: map[string]time.Time{}}
+}
+
+func (m *memoryLoginAttemptRepository) GetLockedUntil(keys ...string) (time.Time, error) {
+	var latest time.Time
+	for _, key := range keys {
+		if until := m.lockedUntil[key]; until.After(time.Now()) && until.After(latest) {
+			latest = until
+		}
+	}
+	return latest, nil
+}
+
+func (m *memoryLoginAttemptRepository) RegisterFailure(key string, threshold int, baseLockout time.Duration, maxLockout time.Duration) (model.LoginAttempt, error) {
+	m.failures[key]++
+	if m.failures[key] >= threshold {
+		m.lockedUntil[key] = time.Now().Add(baseLockout)
+	}
+	return model.LoginAttempt{}, nil
+}
+
+func (m *memoryLoginAttemptRepository) ResetFailures(key string) error {
+	delete(m.failures, key)
+	delete(m.lockedUntil, key)
+	return nil
+}
+
+func (m *memoryLoginAttemptRepository) CreateLoginHistory(history *model.LoginHistory) (model.LoginHistory, error) {
+	m.outcomes = append(m.outcomes, history.Outcome)
+	return *history, nil
+}
+
+func TestConfirmEnrollmentLocksAfterFailedAttempts(t *testing.T) {
+	cfg := config.Config{
+		TokenConfig: config.TokenConfig{MFAEncryptionKey: []byte("test-mfa-key")},
+		LoginConfig: config.LoginConfig{MaxLoginAttempts: 3, LoginLockoutBase: time.Minute, LoginLockoutMax: time.Hour},
+	}
+
+	secret, err := service.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+	encrypted, err := service.EncryptSecret(cfg.MFAEncryptionKey, secret)
+	if err != nil {
+		t.Fatalf("EncryptSecret: %v", err)
+	}
+
+	attempts := newMemoryLoginAttemptRepository()
+	useCase := NewMFAUseCase(&fakeMFARepository{mfa: model.UserMFA{UserID: 1, Secret: encrypted}}, nil, attempts, cfg)
+
+	for i := 0; i < cfg.MaxLoginAttempts; i++ {
+		_, err = useCase.ConfirmEnrollment(1, "abcdef")
+		if !errors.Is(err, apperror.ErrUnauthorized) {
+			t.Fatalf("attempt %d error = %v, want unauthorized", i+1, err)
+		}
+	}
+
+	_, err = useCase.ConfirmEnrollment(1, "abcdef")
+	if !errors.Is(err, apperror.ErrTooManyRequests) {
+		t.Fatalf("locked ConfirmEnrollment error = %v, want too many requests", err)
+	}
+
+	// Counter yang sama dipakai VerifyCode, jadi lockout tidak bisa dihindari lewat endpoint lain
+	err = useCase.VerifyCode(1, "abcdef")
+	if !errors.Is(err, apperror.ErrTooManyRequests) {
+		t.Errorf("VerifyCode error = %v, want too many requests", err)
+	}
+}
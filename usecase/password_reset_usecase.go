@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"edu-learn/config"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/service"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type passwordResetUseCase struct {
+	repo              repository.PasswordResetRepository
+	repoUser          repository.UserRepository
+	mailUseCase       MailUseCase
+	revocationService service.TokenRevocationService
+	passwordService   service.PasswordService
+	cfg               config.Config
+}
+
+type PasswordResetUseCase interface {
+	ForgotPassword(email string) error
+	ResetPassword(token string, password string) error
+}
+
+// ForgotPassword => Selalu sukses walaupun email tidak terdaftar supaya akun tidak bisa ditebak
+func (p *passwordResetUseCase) ForgotPassword(email string) error {
+	user, err := p.repoUser.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Service account tidak punya password yang bisa dipakai
+	if user.AccountType == model.AccountTypeService {
+		return nil
+	}
+
+	token, err := newSecureToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = p.repo.CreateResetToken(&model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashSecureToken(token),
+		ExpiresAt: time.Now().Add(p.cfg.ResetTokenLifeTime),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", p.cfg.AppBaseURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Halo %s,\n\nKami menerima permintaan untuk mengatur ulang password akun EduLearn Anda.\n"+
+		"Buka link berikut dalam %d menit:\n\n%s\n\nAbaikan email ini jika Anda tidak meminta reset password.",
+		user.Name, int(p.cfg.ResetTokenLifeTime.Minutes()), link)
+
+	return p.mailUseCase.QueueEmail(user.Email, "Reset password EduLearn", body)
+}
+
+func (p *passwordResetUseCase) ResetPassword(token string, password string) error {
+	if password == "" {
+		return apperror.BadRequest("password are required")
+	}
+
+	resetToken, err := p.repo.GetResetTokenByHash(hashSecureToken(token))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return apperror.BadRequest("invalid or expired reset token")
+		}
+		return err
+	}
+
+	if resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		return apperror.BadRequest("invalid or expired reset token")
+	}
+
+	user, err := p.repoUser.GetUserById(resetToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = p.passwordService.Validate(password, user.Email)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := p.passwordService.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.repo.ResetPassword(resetToken.ID, hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	// Password baru => semua sesi lama harus login ulang
+	return p.revocationService.RevokeUserTokens(resetToken.UserID)
+}
+
+// newSecureToken => Token acak untuk link email, yang disimpan hanya hash-nya
+func newSecureToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashSecureToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewPasswordResetUseCase(repo repository.PasswordResetRepository, repoUser repository.UserRepository, mailUseCase MailUseCase, revocationService service.TokenRevocationService, passwordService service.PasswordService, cfg config.Config) PasswordResetUseCase {
+	return &passwordResetUseCase{repo: repo, repoUser: repoUser, mailUseCase: mailUseCase, revocationService: revocationService, passwordService: passwordService, cfg: cfg}
+}
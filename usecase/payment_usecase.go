@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"edu-learn/config"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/service"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+type paymentUseCase struct {
+	repo       repository.PaymentRepository
+	repoRefund repository.RefundRepository
+	repoCourse repository.CourseRepository
+	repoEnroll repository.EnrollmentRepository
+	gateway    service.PaymentGateway
+	cfg        config.PaymentConfig
+}
+
+type PaymentUseCase interface {
+	CreatePayment(payment *model.Payment) (model.Payment, error)
+	GetPaymentById(id int, actor model.User) (model.Payment, error)
+	GetPaymentsByUserID(userID int, page modelutils.PageQuery, actor model.User) ([]model.Payment, int64, error)
+	HandleWebhook(header http.Header, payload []byte) error
+	RefundPayment(paymentID int, refund *model.Refund, actor model.User) (model.Refund, error)
+	GetRefundsByPaymentID(paymentID int, page modelutils.PageQuery, actor model.User) ([]model.Refund, int64, error)
+}
+
+func (p *paymentUseCase) CreatePayment(payment *model.Payment) (model.Payment, error) {
+	course, err := p.repoCourse.GetCourseById(payment.CourseID)
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	if course.Price <= 0 {
+		return model.Payment{}, apperror.BadRequest("course is free")
+	}
+
+	// Amount dari client opsional, tapi jika dikirim harus sama dengan harga kursus
+	if payment.Amount != 0 && payment.Amount != course.Price {
+		return model.Payment{}, apperror.BadRequest("invalid payment amount")
+	}
+
+	enrolled, err := p.repoEnroll.IsEnrolled(payment.StudentID, payment.CourseID)
+	if err != nil {
+		return model.Payment{}, err
+	}
+	if enrolled {
+		return model.Payment{}, apperror.Conflict("user already enrolled in course")
+	}
+
+	paid, err := p.repo.HasCompletedPayment(payment.StudentID, payment.CourseID)
+	if err != nil {
+		return model.Payment{}, err
+	}
+	if paid {
+		return model.Payment{}, apperror.Conflict("course already paid")
+	}
+
+	// Satu charge pending per siswa dan kursus, retry harus menunggu charge sebelumnya selesai atau gagal
+	pending, err := p.repo.HasPendingPayment(payment.StudentID, payment.CourseID)
+	if err != nil {
+		return model.Payment{}, err
+	}
+	if pending {
+		return model.Payment{}, apperror.Conflict("payment for this course is still pending")
+	}
+
+	payment.Amount = course.Price
+	payment.Status = model.PaymentStatusPending
+	payment.Gateway = p.gateway.Name()
+
+	created, err := p.repo.CreatePayment(payment)
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	charge, err := p.gateway.CreateCharge(created)
+	if err != nil {
+		p.repo.UpdatePayment(created.ID, &model.Payment{Status: model.PaymentStatusFailed})
+		return model.Payment{}, fmt.Errorf("failed to create charge: %w", err)
+	}
+
+	update := &model.Payment{
+		Status:     charge.Status,
+		GatewayRef: charge.Reference,
+		PaymentURL: charge.PaymentURL,
+	}
+
+	// Pembayaran yang langsung selesai sekaligus membuat enrollment
+	if charge.Status == model.PaymentStatusCompleted {
+		return p.repo.CompletePayment(created.ID, update)
+	}
+
+	return p.repo.UpdatePayment(created.ID, update)
+}
+
+// GetPaymentById => Siswa melihat pembayarannya sendiri, instruktur pembayaran untuk kursusnya
+func (p *paymentUseCase) GetPaymentById(id int, actor model.User) (model.Payment, error) {
+	payment, err := p.getPayment(id)
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	isOwner := payment.StudentID == actor.ID || (payment.Course != nil && payment.Course.InstructorID == actor.ID)
+	err = policy.Authorize(actor, policy.PaymentRead, isOwner)
+	if err != nil {
+		// Pembayaran milik orang lain dianggap tidak ada
+		return model.Payment{}, apperror.NotFound("payment not found")
+	}
+
+	return payment, nil
+}
+
+func (p *paymentUseCase) GetPaymentsByUserID(userID int, page modelutils.PageQuery, actor model.User) ([]model.Payment, int64, error) {
+	err := policy.Authorize(actor, policy.PaymentRead, userID == actor.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return p.repo.GetPaymentsByUserID(userID, page)
+}
+
+// HandleWebhook => Signature dan format notifikasi dicek oleh gateway yang dikonfigurasi
+func (p *paymentUseCase) HandleWebhook(header http.Header, payload []byte) error {
+	event, err := p.gateway.ParseWebhook(header, payload)
+	if err != nil {
+		return err
+	}
+
+	switch event.Status {
+	case model.PaymentStatusPending, model.PaymentStatusCompleted, model.PaymentStatusFailed, model.PaymentStatusRefunded:
+	default:
+		return apperror.BadRequest("invalid webhook payload")
+	}
+
+	payment, err := p.repo.GetPaymentByGatewayRef(event.Reference)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.repo.ApplyPaymentEvent(payment.ID, event.EventID, event.Status)
+	return err
+}
+
+func (p *paymentUseCase) RefundPayment(paymentID int, refund *model.Refund, actor model.User) (model.Refund, error) {
+	payment, err := p.getManagedPayment(paymentID, actor)
+	if err != nil {
+		return model.Refund{}, err
+	}
+
+	if payment.Status != model.PaymentStatusCompleted {
+		return model.Refund{}, apperror.Validation("payment is not refundable")
+	}
+
+	// Batas waktu dihitung sejak pembayaran selesai, bukan sejak checkout dibuat
+	if payment.CompletedAt == nil || time.Since(*payment.CompletedAt) > p.cfg.RefundWindow {
+		return model.Refund{}, apperror.Validation("refund window has expired")
+	}
+
+	refund.PaymentID = payment.ID
+	refund.ActorID = &actor.ID
+
+	// Saldo dipotong dulu dengan refund pending, baru gateway dipanggil
+	reserved, err := p.repoRefund.ReserveRefund(refund)
+	if err != nil {
+		return model.Refund{}, err
+	}
+
+	err = p.gateway.Refund(payment, reserved.Amount, reserved.Reason)
+	if err != nil {
+		failErr := p.repoRefund.FailRefund(reserved.ID)
+		if failErr != nil {
+			log.Printf("failed to release refund %d: %v", reserved.ID, failErr)
+		}
+		return model.Refund{}, fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	// Jika gagal di sini refund tetap pending dan saldonya tetap terpotong, jadi tidak bisa dibayar dua kali
+	return p.repoRefund.CompleteRefund(reserved.ID)
+}
+
+func (p *paymentUseCase) GetRefundsByPaymentID(paymentID int, page modelutils.PageQuery, actor model.User) ([]model.Refund, int64, error) {
+	_, err := p.getManagedPayment(paymentID, actor)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return p.repoRefund.GetRefundsByPaymentID(paymentID, page)
+}
+
+func (p *paymentUseCase) getPayment(id int) (model.Payment, error) {
+	if id <= 0 {
+		return model.Payment{}, apperror.BadRequest("invalid payment id")
+	}
+
+	return p.repo.GetPaymentById(id)
+}
+
+// getManagedPayment => Instruktur hanya boleh mengelola pembayaran untuk kursus miliknya
+func (p *paymentUseCase) getManagedPayment(paymentID int, actor model.User) (model.Payment, error) {
+	payment, err := p.getPayment(paymentID)
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	err = policy.Authorize(actor, policy.PaymentRefund, payment.Course != nil && payment.Course.InstructorID == actor.ID)
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	return payment, nil
+}
+
+func NewPaymentUseCase(repo repository.PaymentRepository, repoRefund repository.RefundRepository, repoCourse repository.CourseRepository, repoEnroll repository.EnrollmentRepository, gateway service.PaymentGateway, cfg config.PaymentConfig) PaymentUseCase {
+	return &paymentUseCase{repo: repo, repoRefund: repoRefund, repoCourse: repoCourse, repoEnroll: repoEnroll, gateway: gateway, cfg: cfg}
+}
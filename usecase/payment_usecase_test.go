@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"edu-learn/config"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/service"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// memoryPaymentStore => Meniru aturan refund di repository untuk satu pembayaran: refund pending dan
+// completed memotong saldo, refund penuh mengubah status pembayaran dan mencabut enrollment
+type memoryPaymentStore struct {
+	repository.PaymentRepository
+	repository.RefundRepository
+	payment  model.Payment
+	refunds  []model.Refund
+	enrolled bool
+}
+
+func (m *memoryPaymentStore) GetPaymentById(id int) (model.Payment, error) {
+	if id != m.payment.ID {
+		return model.Payment{}, apperror.NotFound("payment not found")
+	}
+	return m.payment, nil
+}
+
+func (m *memoryPaymentStore) sumRefunds(statuses ...string) int64 {
+	var total int64
+	for _, refund := range m.refunds {
+		for _, status := range statuses {
+			if refund.Status == status {
+				total += toCents(refund.Amount)
+			}
+		}
+	}
+	return total
+}
+
+func (m *memoryPaymentStore) ReserveRefund(refund *model.Refund) (model.Refund, error) {
+	if m.payment.Status != model.PaymentStatusCompleted {
+		return model.Refund{}, apperror.Validation("payment is not refundable")
+	}
+
+	remaining := toCents(m.payment.Amount) - m.sumRefunds(model.RefundStatusPending, model.RefundStatusCompleted)
+	if refund.Amount == 0 {
+		refund.Amount = float64(remaining) / 100
+	}
+	if refund.Amount <= 0 || toCents(refund.Amount) > remaining {
+		return model.Refund{}, apperror.Validation("refund amount exceeds remaining balance")
+	}
+
+	refund.ID = len(m.refunds) + 1
+	refund.Status = model.RefundStatusPending
+	m.refunds = append(m.refunds, *refund)
+	return *refund, nil
+}
+
+func (m *memoryPaymentStore) CompleteRefund(id int) (model.Refund, error) {
+	m.refunds[id-1].Status = model.RefundStatusCompleted
+
+	if m.sumRefunds(model.RefundStatusCompleted) >= toCents(m.payment.Amount) {
+		m.payment.Status = model.PaymentStatusRefunded
+		m.enrolled = false
+	}
+	return m.refunds[id-1], nil
+}
+
+func (m *memoryPaymentStore) FailRefund(id int) error {
+	if m.refunds[id-1].Status == model.RefundStatusPending {
+		m.refunds[id-1].Status = model.RefundStatusFailed
+	}
+	return nil
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+type fakeRefundGateway struct {
+	service.PaymentGateway
+	err     error
+	refunds []float64
+}
+
+func (f *fakeRefundGateway) Refund(payment model.Payment, amount float64, reason string) error {
+	f.refunds = append(f.refunds, amount)
+	return f.err
+}
+
+func newRefundTestStore(completedAgo time.Duration, refunds ...model.Refund) *memoryPaymentStore {
+	completedAt := time.Now().Add(-completedAgo)
+	return &memoryPaymentStore{
+		payment: model.Payment{
+			ID:          1,
+			StudentID:   7,
+			CourseID:    3,
+			Course:      &model.Course{ID: 3, InstructorID: 5},
+			Amount:      150000,
+			Status:      model.PaymentStatusCompleted,
+			PaymentDate: completedAt.Add(-time.Hour),
+			CompletedAt: &completedAt,
+		},
+		refunds:  refunds,
+		enrolled: true,
+	}
+}
+
+func TestRefundPayment(t *testing.T) {
+	admin := model.User{ID: 1, Role: "admin"}
+	cfg := config.PaymentConfig{RefundWindow: 14 * 24 * time.Hour}
+
+	tests := []struct {
+		name          string
+		store         *memoryPaymentStore
+		amount        float64
+		actor         model.User
+		wantErr       error
+		wantAmount    float64
+		wantPayment   string
+		wantEnrolled  bool
+		wantGatewayOK bool
+	}{
+		{
+			name: "full refund revokes enrollment", store: newRefundTestStore(time.Hour), actor: admin,
+			wantAmount: 150000, wantPayment: model.PaymentStatusRefunded, wantEnrolled: false, wantGatewayOK: true,
+		},
+		{
+			name: "partial refund keeps enrollment", store: newRefundTestStore(time.Hour), amount: 50000, actor: admin,
+			wantAmount: 50000, wantPayment: model.PaymentStatusCompleted, wantEnrolled: true, wantGatewayOK: true,
+		},
+		{
+			name: "last partial refund completes the refund", store: newRefundTestStore(time.Hour, model.Refund{ID: 1, Amount: 100000, Status: model.RefundStatusCompleted}),
+			actor: admin, wantAmount: 50000, wantPayment: model.PaymentStatusRefunded, wantEnrolled: false, wantGatewayOK: true,
+		},
+		{
+			name: "instructor refunds own course", store: newRefundTestStore(time.Hour), amount: 50000, actor: model.User{ID: 5, Role: "instructor"},
+			wantAmount: 50000, wantPayment: model.PaymentStatusCompleted, wantEnrolled: true, wantGatewayOK: true,
+		},
+		{
+			name: "over refund", store: newRefundTestStore(time.Hour), amount: 150000.01, actor: admin,
+			wantErr: apperror.ErrValidation, wantPayment: model.PaymentStatusCompleted, wantEnrolled: true,
+		},
+		{
+			name: "pending refund holds the balance", store: newRefundTestStore(time.Hour, model.Refund{ID: 1, Amount: 100000, Status: model.RefundStatusPending}),
+			amount: 60000, actor: admin, wantErr: apperror.ErrValidation, wantPayment: model.PaymentStatusCompleted, wantEnrolled: true,
+		},
+		{
+			name: "failed refund releases the balance", store: newRefundTestStore(time.Hour, model.Refund{ID: 1, Amount: 100000, Status: model.RefundStatusFailed}),
+			actor: admin, wantAmount: 150000, wantPayment: model.PaymentStatusRefunded, wantEnrolled: false, wantGatewayOK: true,
+		},
+		{
+			name: "expired window", store: newRefundTestStore(15 * 24 * time.Hour), actor: admin,
+			wantErr: apperror.ErrValidation, wantPayment: model.PaymentStatusCompleted, wantEnrolled: true,
+		},
+		{
+			name: "instructor of another course", store: newRefundTestStore(time.Hour), actor: model.User{ID: 6, Role: "instructor"},
+			wantErr: apperror.ErrForbidden, wantPayment: model.PaymentStatusCompleted, wantEnrolled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := &fakeRefundGateway{}
+			useCase := NewPaymentUseCase(tt.store, tt.store, nil, nil, gateway, cfg)
+
+			refund, err := useCase.RefundPayment(1, &model.Refund{Amount: tt.amount, Reason: "test"}, tt.actor)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("RefundPayment error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("RefundPayment: %v", err)
+			}
+
+			if tt.wantGatewayOK {
+				if refund.Status != model.RefundStatusCompleted || refund.Amount != tt.wantAmount {
+					t.Errorf("refund = %s %.2f, want completed %.2f", refund.Status, refund.Amount, tt.wantAmount)
+				}
+				if len(gateway.refunds) != 1 || gateway.refunds[0] != tt.wantAmount {
+					t.Errorf("gateway refunds = %v, want [%.2f]", gateway.refunds, tt.wantAmount)
+				}
+			} else if len(gateway.refunds) != 0 {
+				t.Errorf("gateway called with %v for a rejected refund", gateway.refunds)
+			}
+
+			if tt.store.payment.Status != tt.wantPayment {
+				t.Errorf("payment status = %s, want %s", tt.store.payment.Status, tt.wantPayment)
+			}
+			if tt.store.enrolled != tt.wantEnrolled {
+				t.Errorf("enrolled = %v, want %v", tt.store.enrolled, tt.wantEnrolled)
+			}
+		})
+	}
+}
+
+func TestRefundPaymentGatewayFailure(t *testing.T) {
+	store := newRefundTestStore(time.Hour)
+	gateway := &fakeRefundGateway{err: errors.New("gateway unavailable")}
+	useCase := NewPaymentUseCase(store, store, nil, nil, gateway, config.PaymentConfig{RefundWindow: 24 * time.Hour})
+	admin := model.User{ID: 1, Role: "admin"}
+
+	_, err := useCase.RefundPayment(1, &model.Refund{Reason: "test"}, admin)
+	if err == nil {
+		t.Fatal("RefundPayment succeeded with a failing gateway")
+	}
+
+	if len(store.refunds) != 1 || store.refunds[0].Status != model.RefundStatusFailed {
+		t.Fatalf("refunds = %+v, want one failed refund", store.refunds)
+	}
+	if store.payment.Status != model.PaymentStatusCompleted || !store.enrolled {
+		t.Errorf("payment %s enrolled %v, want completed and still enrolled", store.payment.Status, store.enrolled)
+	}
+
+	// Saldo refund yang gagal dilepas, jadi refund penuh bisa dicoba lagi
+	gateway.err = nil
+	refund, err := useCase.RefundPayment(1, &model.Refund{Reason: "retry"}, admin)
+	if err != nil {
+		t.Fatalf("retry RefundPayment: %v", err)
+	}
+	if refund.Amount != 150000 || store.payment.Status != model.PaymentStatusRefunded || store.enrolled {
+		t.Errorf("retry refunded %.2f, payment %s, enrolled %v", refund.Amount, store.payment.Status, store.enrolled)
+	}
+}
+
+func TestRefundPaymentWindowStartsAtCompletion(t *testing.T) {
+	store := newRefundTestStore(time.Hour)
+	// Checkout dibuat lama sebelum pembayaran selesai, batas waktu tetap dihitung dari completed_at
+	store.payment.PaymentDate = time.Now().Add(-30 * 24 * time.Hour)
+	useCase := NewPaymentUseCase(store, store, nil, nil, &fakeRefundGateway{}, config.PaymentConfig{RefundWindow: 14 * 24 * time.Hour})
+
+	_, err := useCase.RefundPayment(1, &model.Refund{Amount: 50000, Reason: "test"}, model.User{ID: 1, Role: "admin"})
+	if err != nil {
+		t.Errorf("RefundPayment: %v", err)
+	}
+}
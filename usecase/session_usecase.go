@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/policy"
+	"edu-learn/utils/service"
+)
+
+type sessionUseCase struct {
+	repo              repository.SessionRepository
+	repoUser          repository.UserRepository
+	revocationService service.TokenRevocationService
+}
+
+type SessionUseCase interface {
+	GetSessions(userID int, currentSessionID string, page modelutils.PageQuery, actor model.User) ([]model.Session, int64, error)
+	RevokeSession(userID int, sessionID string, actor model.User) error
+	RevokeAllSessions(userID int, actor model.User) error
+}
+
+// GetSessions => Sesi aktif milik user, sesi yang dipakai request ini ditandai current
+func (s *sessionUseCase) GetSessions(userID int, currentSessionID string, page modelutils.PageQuery, actor model.User) ([]model.Session, int64, error) {
+	err := s.authorize(userID, actor)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sessions, total, err := s.repo.GetActiveSessionsByUserID(userID, page)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = currentSessionID != "" && sessions[i].ID == currentSessionID
+	}
+
+	return sessions, total, nil
+}
+
+// RevokeSession => Sesi milik user lain dianggap tidak ada
+func (s *sessionUseCase) RevokeSession(userID int, sessionID string, actor model.User) error {
+	err := s.authorize(userID, actor)
+	if err != nil {
+		return err
+	}
+
+	session, err := s.repo.GetSessionById(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return apperror.NotFound("session not found")
+	}
+	if session.RevokedAt != nil {
+		return apperror.Conflict("session already revoked")
+	}
+
+	return s.revocationService.RevokeSession(sessionID)
+}
+
+// RevokeAllSessions => Logout dari semua perangkat, termasuk sesi yang sedang dipakai
+func (s *sessionUseCase) RevokeAllSessions(userID int, actor model.User) error {
+	err := s.authorize(userID, actor)
+	if err != nil {
+		return err
+	}
+
+	return s.revocationService.RevokeUserTokens(userID)
+}
+
+// authorize => User mengelola sesi sendiri, admin bisa mengelola sesi siapa saja
+func (s *sessionUseCase) authorize(userID int, actor model.User) error {
+	if userID <= 0 {
+		return apperror.BadRequest("invalid user id")
+	}
+
+	if userID == actor.ID {
+		return nil
+	}
+
+	err := policy.Authorize(actor, policy.SessionManage, false)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repoUser.GetUserById(userID)
+	return err
+}
+
+func NewSessionUseCase(repo repository.SessionRepository, repoUser repository.UserRepository, revocationService service.TokenRevocationService) SessionUseCase {
+	return &sessionUseCase{repo: repo, repoUser: repoUser, revocationService: revocationService}
+}
@@ -3,28 +3,34 @@ package usecase
 import (
 	"edu-learn/model"
 	"edu-learn/repository"
-	"fmt"
-
-	"golang.org/x/crypto/bcrypt"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/service"
+	"log"
 )
 
 type userUseCase struct {
-	repo repository.UserRepository
+	repo              repository.UserRepository
+	revocationService service.TokenRevocationService
+	passwordService   service.PasswordService
+	emailVerification EmailVerificationUseCase
 }
 
 type UserUseCase interface {
 	GetUserByEmail(email string) (model.User, error)
 	CreateUser(user *model.User) (model.User, error)
-	GetAllUsers() ([]model.User, error)
+	CreateExternalUser(user *model.User) (model.User, error)
+	GetAllUsers(page modelutils.PageQuery) ([]model.User, int64, error)
 	GetUserById(id int) (model.User, error)
 	UpdateUser(id int, user *model.User) (model.User, error)
 	DeleteUser(id int) error
+	RehashPassword(user model.User, password string) error
 }
 
 func (u *userUseCase) GetUserByEmail(email string) (model.User, error) {
 	// Dapat diubah jika user login dengan email
 	if email == "" {
-		return model.User{}, fmt.Errorf("email are required")
+		return model.User{}, apperror.BadRequest("email are required")
 	}
 
 	return u.repo.GetUserByEmail(email)
@@ -32,24 +38,43 @@ func (u *userUseCase) GetUserByEmail(email string) (model.User, error) {
 
 // Method untuk user usecase
 func (u *userUseCase) CreateUser(user *model.User) (model.User, error) {
+	err := u.passwordService.Validate(user.Password, user.Email)
+	if err != nil {
+		return model.User{}, err
+	}
+
 	// Hash password sebelum menyimpan ke database
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	user.Password, err = u.passwordService.Hash(user.Password)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return u.repo.CreateUser(user)
+}
+
+// CreateExternalUser => User yang tidak login dengan password (OIDC, service account), diberi password acak yang tidak pernah dipakai
+func (u *userUseCase) CreateExternalUser(user *model.User) (model.User, error) {
+	password, err := newSecureToken()
+	if err != nil {
+		return model.User{}, err
+	}
+
+	user.Password, err = u.passwordService.Hash(password)
 	if err != nil {
-		return model.User{}, fmt.Errorf("failed to hash password")
+		return model.User{}, err
 	}
-	user.Password = string(hashedPassword)
 
 	return u.repo.CreateUser(user)
 }
 
-func (u *userUseCase) GetAllUsers() ([]model.User, error) {
-	return u.repo.GetAllUsers()
+func (u *userUseCase) GetAllUsers(page modelutils.PageQuery) ([]model.User, int64, error) {
+	return u.repo.GetAllUsers(page)
 }
 
 func (u *userUseCase) GetUserById(id int) (model.User, error) {
 	// Dapat diubah jika user login dengan email
 	if id == 0 {
-		return model.User{}, fmt.Errorf("id are required")
+		return model.User{}, apperror.BadRequest("id are required")
 	}
 
 	return u.repo.GetUserById(id)
@@ -58,30 +83,86 @@ func (u *userUseCase) GetUserById(id int) (model.User, error) {
 func (u *userUseCase) UpdateUser(id int, user *model.User) (model.User, error) {
 	existingUser, err := u.repo.GetUserById(id)
 	if err != nil {
-		return model.User{}, fmt.Errorf("user not found")
+		return model.User{}, err
 	}
 
-	if existingUser.Role != user.Role {
-		return model.User{}, fmt.Errorf("you may not change your role")
-	}
+	// Role dan jenis akun tidak bisa diubah lewat update profil
+	user.Role = existingUser.Role
+	user.AccountType = existingUser.AccountType
 
-	if user.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	passwordChanged := user.Password != ""
+	if passwordChanged {
+		email := user.Email
+		if email == "" {
+			email = existingUser.Email
+		}
+
+		err = u.passwordService.Validate(user.Password, email)
 		if err != nil {
-			return model.User{}, fmt.Errorf("failed to hash password")
+			return model.User{}, err
+		}
+
+		user.Password, err = u.passwordService.Hash(user.Password)
+		if err != nil {
+			return model.User{}, err
 		}
-		user.Password = string(hashedPassword)
 	} else {
 		user.Password = existingUser.Password
 	}
 
-	return u.repo.UpdateUser(id, user)
+	updatedUser, err := u.repo.UpdateUser(id, user)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	// Ganti password => semua sesi lama harus login ulang
+	if passwordChanged {
+		err = u.revocationService.RevokeUserTokens(id)
+		if err != nil {
+			return model.User{}, err
+		}
+	}
+
+	// Ganti email => status verifikasi sudah direset di repository, link verifikasi dikirim ke email baru
+	if user.Email != "" && user.Email != existingUser.Email {
+		err = u.emailVerification.SendVerificationEmail(updatedUser)
+		if err != nil {
+			log.Printf("failed to send verification email to user %d: %v", id, err)
+		}
+	}
+
+	return updatedUser, nil
 }
 
 func (u *userUseCase) DeleteUser(id int) error {
+	_, err := u.repo.GetUserById(id)
+	if err != nil {
+		return err
+	}
+
+	// Cabut token dulu supaya user yang dihapus tidak bisa memakai token lamanya
+	err = u.revocationService.RevokeUserTokens(id)
+	if err != nil {
+		return err
+	}
+
 	return u.repo.DeleteUser(id)
 }
 
-func NewUserUseCase(repo repository.UserRepository) UserUseCase {
-	return &userUseCase{repo: repo}
+// RehashPassword => Dipanggil setelah login berhasil, hash hanya diganti jika BCRYPT_COST berubah sejak password disimpan
+func (u *userUseCase) RehashPassword(user model.User, password string) error {
+	if !u.passwordService.NeedsRehash(user.Password) {
+		return nil
+	}
+
+	hashedPassword, err := u.passwordService.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return u.repo.UpdatePassword(user.ID, hashedPassword)
+}
+
+func NewUserUseCase(repo repository.UserRepository, revocationService service.TokenRevocationService, passwordService service.PasswordService, emailVerification EmailVerificationUseCase) UserUseCase {
+	return &userUseCase{repo: repo, revocationService: revocationService, passwordService: passwordService, emailVerification: emailVerification}
 }
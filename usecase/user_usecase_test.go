@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"edu-learn/model"
+	"edu-learn/repository"
+	"testing"
+	"time"
+)
+
+type fakeUserRepository struct {
+	repository.UserRepository
+	user model.User
+}
+
+func (f *fakeUserRepository) GetUserById(id int) (model.User, error) {
+	return f.user, nil
+}
+
+// UpdateUser => Meniru repository: email yang berubah mereset status verifikasi
+func (f *fakeUserRepository) UpdateUser(id int, user *model.User) (model.User, error) {
+	if user.Email != "" && user.Email != f.user.Email {
+		f.user.Email = user.Email
+		f.user.EmailVerifiedAt = nil
+	}
+	if user.Name != "" {
+		f.user.Name = user.Name
+	}
+	return f.user, nil
+}
+
+func TestUpdateUserEmailChangeRequiresVerification(t *testing.T) {
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name     string
+		update   model.User
+		wantSent []string
+	}{
+		{"email changed", model.User{Email: "budi.baru@example.com"}, []string{"budi.baru@example.com"}},
+		{"same email", model.User{Email: "budi@example.com", Name: "Budi S"}, nil},
+		{"email omitted", model.User{Name: "Budi S"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeUserRepository{user: model.User{ID: 1, Name: "Budi", Email: "budi@example.com", Role: "student", EmailVerifiedAt: &verifiedAt}}
+			verifier := &fakeEmailVerificationUseCase{}
+			useCase := NewUserUseCase(repo, nil, nil, verifier)
+
+			update := tt.update
+			updated, err := useCase.UpdateUser(1, &update)
+			if err != nil {
+				t.Fatalf("UpdateUser: %v", err)
+			}
+
+			if len(verifier.sent) != len(tt.wantSent) || (len(tt.wantSent) == 1 && verifier.sent[0] != tt.wantSent[0]) {
+				t.Errorf("verification emails = %v, want %v", verifier.sent, tt.wantSent)
+			}
+			if verified := updated.EmailVerifiedAt != nil; verified != (tt.wantSent == nil) {
+				t.Errorf("email verified = %v, want %v", verified, tt.wantSent == nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package apperror
+
+import (
+	"edu-learn/utils/validation"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Jenis error domain, dicek dengan errors.Is lalu diubah menjadi status HTTP oleh middleware.ErrorHandler
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrForbidden       = errors.New("forbidden")
+	ErrValidation      = errors.New("validation failed")
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrPaymentRequired = errors.New("payment required")
+	ErrTooManyRequests = errors.New("too many requests")
+)
+
+// Error => Pesan untuk client beserta jenisnya, Fields hanya diisi untuk error validasi body
+type Error struct {
+	Kind    error
+	Message string
+	Fields  []validation.FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func New(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func NotFound(format string, args ...interface{}) error {
+	return New(ErrNotFound, format, args...)
+}
+
+func Conflict(format string, args ...interface{}) error {
+	return New(ErrConflict, format, args...)
+}
+
+func Forbidden(format string, args ...interface{}) error {
+	return New(ErrForbidden, format, args...)
+}
+
+func Validation(format string, args ...interface{}) error {
+	return New(ErrValidation, format, args...)
+}
+
+func BadRequest(format string, args ...interface{}) error {
+	return New(ErrBadRequest, format, args...)
+}
+
+func Unauthorized(format string, args ...interface{}) error {
+	return New(ErrUnauthorized, format, args...)
+}
+
+func PaymentRequired(format string, args ...interface{}) error {
+	return New(ErrPaymentRequired, format, args...)
+}
+
+func TooManyRequests(format string, args ...interface{}) error {
+	return New(ErrTooManyRequests, format, args...)
+}
+
+// InvalidFields => Body yang gagal validasi, dibalas 422 beserta daftar field
+func InvalidFields(fields []validation.FieldError) error {
+	return &Error{Kind: ErrValidation, Message: "Validation failed", Fields: fields}
+}
+
+// StatusCode => Error tanpa jenis dianggap error internal
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrPaymentRequired):
+		return http.StatusPaymentRequired
+	case errors.Is(err, ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,35 @@
+package modelutils
+
+import (
+	"edu-learn/model"
+	"time"
+)
+
+// Urutan list kursus yang bisa dipilih client lewat ?sort=
+const (
+	CourseSortNewest     = "newest"
+	CourseSortPriceAsc   = "price_asc"
+	CourseSortPriceDesc  = "price_desc"
+	CourseSortTitle      = "title"
+	CourseSortPopularity = "popularity"
+)
+
+// CourseFilter => Filter list kursus publik, field kosong/nil berarti tidak difilter
+type CourseFilter struct {
+	Category     string
+	InstructorID int
+	MinPrice     *float64
+	MaxPrice     *float64
+	Free         *bool
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time // Inklusif sampai akhir hari
+	Sort         string
+}
+
+// CourseSearchResult => Satu hasil pencarian, TitleHighlight dan Snippet sudah di-escape, hanya kata yang cocok diberi tag <mark>
+type CourseSearchResult struct {
+	Course         model.Course
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
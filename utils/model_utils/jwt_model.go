@@ -1,9 +1,72 @@
 package modelutils
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
 
 type JWTPayloadClaim struct {
 	jwt.RegisteredClaims
 	UserId int
 	Role   string
+
+	// Sesi login (family refresh token), kosong untuk token MFA dan impersonation
+	SessionID string `json:"sid,omitempty"`
+
+	// Token sementara setelah password benar, hanya untuk verifikasi kode MFA
+	MFAPending bool `json:"mfa_pending,omitempty"`
+
+	// Diisi saat admin login sebagai user lain (RFC 8693), UserId tetap milik user yang ditiru
+	Act *ActClaim `json:"act,omitempty"`
+}
+
+// ActClaim => Admin yang sebenarnya melakukan request
+type ActClaim struct {
+	Subject string `json:"sub"`
+	UserId  int    `json:"user_id"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// MFAChallenge => Dikembalikan saat login butuh kode MFA atau user wajib mendaftarkan MFA
+type MFAChallenge struct {
+	MFAToken           string `json:"mfa_token"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+}
+
+// LoginResult => Berisi token jika login selesai, atau MFA jika masih butuh langkah kedua
+type LoginResult struct {
+	Tokens TokenPair
+	MFA    *MFAChallenge
+}
+
+// ImpersonationToken => Access token berumur pendek tanpa refresh token
+type ImpersonationToken struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// JWK => Format JSON Web Key (RFC 7517) untuk endpoint /.well-known/jwks.json
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
 }
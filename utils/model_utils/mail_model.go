@@ -0,0 +1,8 @@
+package modelutils
+
+type MailMessage struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
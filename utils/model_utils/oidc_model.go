@@ -0,0 +1,10 @@
+package modelutils
+
+// OIDCIdentity => Klaim dari ID token yang sudah diverifikasi
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Nonce         string
+}
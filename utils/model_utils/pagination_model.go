@@ -0,0 +1,14 @@
+package modelutils
+
+// DefaultPerPage => Dipakai jika client tidak mengirim per_page, batas atasnya ada di dto.PageDto
+const DefaultPerPage = 20
+
+// PageQuery => Halaman yang diminta client, Page dimulai dari 1
+type PageQuery struct {
+	Page    int
+	PerPage int
+}
+
+func (p PageQuery) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
@@ -0,0 +1,15 @@
+package modelutils
+
+// PaymentCharge => Hasil pembuatan transaksi di payment gateway
+type PaymentCharge struct {
+	Reference  string
+	PaymentURL string
+	Status     string
+}
+
+// PaymentWebhookEvent => Notifikasi gateway yang sudah diverifikasi dan dipetakan ke status pembayaran
+type PaymentWebhookEvent struct {
+	EventID   string
+	Reference string
+	Status    string
+}
@@ -0,0 +1,155 @@
+package policy
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+)
+
+type Permission string
+
+const (
+	CourseCreate Permission = "course:create"
+	CourseUpdate Permission = "course:update"
+	CourseDelete Permission = "course:delete"
+
+	MaterialCreate Permission = "material:create"
+	MaterialRead   Permission = "material:read"
+	MaterialUpdate Permission = "material:update"
+	MaterialDelete Permission = "material:delete"
+
+	EnrollmentCreate Permission = "enrollment:create"
+
+	PaymentCreate Permission = "payment:create"
+	PaymentRead   Permission = "payment:read"
+	PaymentRefund Permission = "payment:refund"
+
+	UserList   Permission = "user:list"
+	UserRead   Permission = "user:read"
+	UserUpdate Permission = "user:update"
+	UserDelete Permission = "user:delete"
+	UserUnlock Permission = "user:unlock"
+
+	MFAPolicyManage Permission = "mfa:policy"
+
+	ApplicationCreate Permission = "instructor_application:create"
+	ApplicationRead   Permission = "instructor_application:read"
+	ApplicationReview Permission = "instructor_application:review"
+
+	APIKeyManage         Permission = "api_key:manage"
+	ServiceAccountManage Permission = "service_account:manage"
+
+	UserImpersonate Permission = "user:impersonate"
+	AuditLogRead    Permission = "audit_log:read"
+
+	SessionManage Permission = "session:manage"
+)
+
+// Scope => Seberapa luas sebuah permission berlaku
+type Scope int
+
+const (
+	ScopeNone Scope = iota
+	ScopeOwn        // hanya resource milik sendiri (kursus yang diajar, data diri, kursus yang diikuti)
+	ScopeAny        // semua resource
+)
+
+// ErrForbidden => Pesan error 403 dari Authorize
+const ErrForbidden = "forbidden: you do not have access to this resource"
+
+var rolePermissions = map[string]map[Permission]Scope{
+	"admin": {
+		CourseCreate:    ScopeAny,
+		CourseUpdate:    ScopeAny,
+		CourseDelete:    ScopeAny,
+		MaterialCreate:  ScopeAny,
+		MaterialRead:    ScopeAny,
+		MaterialUpdate:  ScopeAny,
+		MaterialDelete:  ScopeAny,
+		PaymentRead:     ScopeAny,
+		PaymentRefund:   ScopeAny,
+		UserList:        ScopeAny,
+		UserRead:        ScopeAny,
+		UserUpdate:      ScopeAny,
+		UserDelete:      ScopeAny,
+		UserUnlock:      ScopeAny,
+		MFAPolicyManage: ScopeAny,
+
+		ApplicationRead:   ScopeAny,
+		ApplicationReview: ScopeAny,
+
+		APIKeyManage:         ScopeAny,
+		ServiceAccountManage: ScopeAny,
+
+		UserImpersonate: ScopeAny,
+		AuditLogRead:    ScopeAny,
+
+		SessionManage: ScopeAny,
+	},
+	"instructor": {
+		CourseCreate:   ScopeOwn,
+		CourseUpdate:   ScopeOwn,
+		CourseDelete:   ScopeOwn,
+		MaterialCreate: ScopeOwn,
+		MaterialRead:   ScopeOwn,
+		MaterialUpdate: ScopeOwn,
+		MaterialDelete: ScopeOwn,
+		PaymentRead:    ScopeOwn,
+		PaymentRefund:  ScopeOwn,
+		UserRead:       ScopeOwn,
+
+		ApplicationRead: ScopeOwn,
+
+		APIKeyManage: ScopeOwn,
+	},
+	"student": {
+		MaterialRead:     ScopeOwn,
+		EnrollmentCreate: ScopeOwn,
+		PaymentCreate:    ScopeOwn,
+		PaymentRead:      ScopeOwn,
+		UserRead:         ScopeOwn,
+
+		ApplicationCreate: ScopeOwn,
+		ApplicationRead:   ScopeOwn,
+
+		APIKeyManage: ScopeOwn,
+	},
+}
+
+// nonDelegable => Permission yang tidak boleh diberikan ke API key supaya key tidak bisa membuat key baru
+var nonDelegable = map[Permission]bool{
+	APIKeyManage:         true,
+	ServiceAccountManage: true,
+	MFAPolicyManage:      true,
+	UserUnlock:           true,
+	UserImpersonate:      true,
+	SessionManage:        true,
+}
+
+// ScopeOf => Scope permission untuk sebuah role, ScopeNone jika tidak punya
+func ScopeOf(role string, permission Permission) Scope {
+	return rolePermissions[role][permission]
+}
+
+// Allows => Role punya permission ini, minimal untuk resource miliknya sendiri
+func Allows(role string, permission Permission) bool {
+	return ScopeOf(role, permission) != ScopeNone
+}
+
+// Delegable => Permission boleh dipakai sebagai scope API key untuk role ini
+func Delegable(role string, permission Permission) bool {
+	return Allows(role, permission) && !nonDelegable[permission]
+}
+
+// Authorize => isOwner menandakan resource milik actor (misalnya kursus yang dia ajar)
+func Authorize(actor model.User, permission Permission, isOwner bool) error {
+	switch ScopeOf(actor.Role, permission) {
+	case ScopeAny:
+		return nil
+	case ScopeOwn:
+		if isOwner {
+			return nil
+		}
+	}
+
+	return apperror.Forbidden(ErrForbidden)
+}
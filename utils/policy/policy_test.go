@@ -0,0 +1,162 @@
+package policy
+
+import (
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	"errors"
+	"testing"
+)
+
+var allPermissions = []Permission{
+	CourseCreate, CourseUpdate, CourseDelete,
+	MaterialCreate, MaterialRead, MaterialUpdate, MaterialDelete,
+	EnrollmentCreate,
+	PaymentCreate, PaymentRead, PaymentRefund,
+	UserList, UserRead, UserUpdate, UserDelete, UserUnlock,
+	MFAPolicyManage,
+	ApplicationCreate, ApplicationRead, ApplicationReview,
+	APIKeyManage, ServiceAccountManage,
+	UserImpersonate, AuditLogRead,
+	SessionManage,
+}
+
+// expectedScopes => Matriks yang diharapkan ditulis ulang di sini, bukan dibaca dari rolePermissions,
+// supaya perubahan hak akses harus disengaja di dua tempat
+var expectedScopes = map[string]map[Permission]Scope{
+	"admin": {
+		CourseCreate:         ScopeAny,
+		CourseUpdate:         ScopeAny,
+		CourseDelete:         ScopeAny,
+		MaterialCreate:       ScopeAny,
+		MaterialRead:         ScopeAny,
+		MaterialUpdate:       ScopeAny,
+		MaterialDelete:       ScopeAny,
+		PaymentRead:          ScopeAny,
+		PaymentRefund:        ScopeAny,
+		UserList:             ScopeAny,
+		UserRead:             ScopeAny,
+		UserUpdate:           ScopeAny,
+		UserDelete:           ScopeAny,
+		UserUnlock:           ScopeAny,
+		MFAPolicyManage:      ScopeAny,
+		ApplicationRead:      ScopeAny,
+		ApplicationReview:    ScopeAny,
+		APIKeyManage:         ScopeAny,
+		ServiceAccountManage: ScopeAny,
+		UserImpersonate:      ScopeAny,
+		AuditLogRead:         ScopeAny,
+		SessionManage:        ScopeAny,
+	},
+	"instructor": {
+		CourseCreate:    ScopeOwn,
+		CourseUpdate:    ScopeOwn,
+		CourseDelete:    ScopeOwn,
+		MaterialCreate:  ScopeOwn,
+		MaterialRead:    ScopeOwn,
+		MaterialUpdate:  ScopeOwn,
+		MaterialDelete:  ScopeOwn,
+		PaymentRead:     ScopeOwn,
+		PaymentRefund:   ScopeOwn,
+		UserRead:        ScopeOwn,
+		ApplicationRead: ScopeOwn,
+		APIKeyManage:    ScopeOwn,
+	},
+	"student": {
+		MaterialRead:      ScopeOwn,
+		EnrollmentCreate:  ScopeOwn,
+		PaymentCreate:     ScopeOwn,
+		PaymentRead:       ScopeOwn,
+		UserRead:          ScopeOwn,
+		ApplicationCreate: ScopeOwn,
+		ApplicationRead:   ScopeOwn,
+		APIKeyManage:      ScopeOwn,
+	},
+	// Role yang tidak dikenal tidak punya permission apa pun
+	"unknown": {},
+	"":        {},
+}
+
+var expectedNonDelegable = map[Permission]bool{
+	APIKeyManage:         true,
+	ServiceAccountManage: true,
+	MFAPolicyManage:      true,
+	UserUnlock:           true,
+	UserImpersonate:      true,
+	SessionManage:        true,
+}
+
+func TestRolePermissionsAreListed(t *testing.T) {
+	listed := map[Permission]bool{}
+	for _, permission := range allPermissions {
+		listed[permission] = true
+	}
+
+	for role, permissions := range rolePermissions {
+		if _, ok := expectedScopes[role]; !ok {
+			t.Errorf("role %q is not covered by expectedScopes", role)
+		}
+		for permission := range permissions {
+			if !listed[permission] {
+				t.Errorf("permission %q of role %q is not in allPermissions", permission, role)
+			}
+		}
+	}
+}
+
+func TestScopeOf(t *testing.T) {
+	for role, scopes := range expectedScopes {
+		for _, permission := range allPermissions {
+			if got, want := ScopeOf(role, permission), scopes[permission]; got != want {
+				t.Errorf("ScopeOf(%q, %q) = %d, want %d", role, permission, got, want)
+			}
+		}
+	}
+}
+
+func TestAllows(t *testing.T) {
+	for role, scopes := range expectedScopes {
+		for _, permission := range allPermissions {
+			want := scopes[permission] != ScopeNone
+			if got := Allows(role, permission); got != want {
+				t.Errorf("Allows(%q, %q) = %v, want %v", role, permission, got, want)
+			}
+		}
+	}
+}
+
+func TestDelegable(t *testing.T) {
+	for role, scopes := range expectedScopes {
+		for _, permission := range allPermissions {
+			want := scopes[permission] != ScopeNone && !expectedNonDelegable[permission]
+			if got := Delegable(role, permission); got != want {
+				t.Errorf("Delegable(%q, %q) = %v, want %v", role, permission, got, want)
+			}
+		}
+	}
+
+	// Admin punya semua permission nonDelegable, tetap tidak boleh diberikan ke API key
+	for permission := range expectedNonDelegable {
+		if Delegable("admin", permission) {
+			t.Errorf("Delegable(admin, %q) = true, want false", permission)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	for role, scopes := range expectedScopes {
+		for _, permission := range allPermissions {
+			for _, isOwner := range []bool{false, true} {
+				scope := scopes[permission]
+				allowed := scope == ScopeAny || (scope == ScopeOwn && isOwner)
+
+				err := Authorize(model.User{ID: 1, Role: role}, permission, isOwner)
+				if allowed && err != nil {
+					t.Errorf("Authorize(%q, %q, owner=%v) = %v, want nil", role, permission, isOwner, err)
+				}
+				if !allowed && !errors.Is(err, apperror.ErrForbidden) {
+					t.Errorf("Authorize(%q, %q, owner=%v) = %v, want forbidden", role, permission, isOwner, err)
+				}
+			}
+		}
+	}
+}
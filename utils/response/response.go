@@ -0,0 +1,99 @@
+package response
+
+import (
+	modelutils "edu-learn/utils/model_utils"
+	"edu-learn/utils/validation"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Envelope => Bentuk body semua response, field yang kosong tidak dikirim
+type Envelope struct {
+	Message string                  `json:"message,omitempty"`
+	Data    interface{}             `json:"data,omitempty"`
+	Meta    *Meta                   `json:"meta,omitempty"`
+	Links   *Links                  `json:"links,omitempty"`
+	Error   string                  `json:"error,omitempty"`
+	Fields  []validation.FieldError `json:"fields,omitempty"`
+}
+
+// Meta => Posisi halaman dan jumlah seluruh data di list
+type Meta struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// Links => URL halaman lain dengan query yang sama, prev/next kosong di halaman pertama/terakhir
+type Links struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last"`
+}
+
+func Success(c *gin.Context, status int, message string, data interface{}) {
+	c.JSON(status, Envelope{Message: message, Data: data})
+}
+
+// Paginated => data berisi satu halaman, total adalah jumlah seluruh data sebelum dipotong
+func Paginated(c *gin.Context, message string, data interface{}, page modelutils.PageQuery, total int64) {
+	totalPages := int((total + int64(page.PerPage) - 1) / int64(page.PerPage))
+
+	// PureJSON supaya & di links tidak di-escape menjadi \u0026
+	c.PureJSON(http.StatusOK, Envelope{
+		Message: message,
+		Data:    data,
+		Meta: &Meta{
+			Page:       page.Page,
+			PerPage:    page.PerPage,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+		Links: pageLinks(c.Request.URL, page, totalPages),
+	})
+}
+
+func Error(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, Envelope{Error: message})
+}
+
+// ErrorWithFields => Error validasi beserta daftar field yang gagal
+func ErrorWithFields(c *gin.Context, status int, message string, fields []validation.FieldError) {
+	c.AbortWithStatusJSON(status, Envelope{Error: message, Fields: fields})
+}
+
+func pageLinks(requestURL *url.URL, page modelutils.PageQuery, totalPages int) *Links {
+	lastPage := totalPages
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	link := func(number int) string {
+		query := requestURL.Query()
+		query.Set("page", strconv.Itoa(number))
+		query.Set("per_page", strconv.Itoa(page.PerPage))
+
+		target := url.URL{Path: requestURL.Path, RawQuery: query.Encode()}
+		return target.String()
+	}
+
+	links := &Links{
+		Self:  link(page.Page),
+		First: link(1),
+		Last:  link(lastPage),
+	}
+	if page.Page > 1 {
+		links.Prev = link(min(page.Page-1, lastPage))
+	}
+	if page.Page < lastPage {
+		links.Next = link(page.Page + 1)
+	}
+
+	return links
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Format key: edu_<prefix>_<secret>, prefix boleh ditampilkan, secret hanya diketahui pemilik
+const apiKeyPrefix = "edu_"
+
+type apiKeyService struct {
+	repo repository.APIKeyRepository
+}
+
+type APIKeyService interface {
+	GenerateAPIKey() (key string, prefix string, err error)
+	HashAPIKey(key string) string
+	Authenticate(key string) (model.APIKey, error)
+}
+
+func (a *apiKeyService) GenerateAPIKey() (string, string, error) {
+	prefixBytes := make([]byte, 6)
+	_, err := rand.Read(prefixBytes)
+	if err != nil {
+		return "", "", err
+	}
+
+	secretBytes := make([]byte, 32)
+	_, err = rand.Read(secretBytes)
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix := apiKeyPrefix + hex.EncodeToString(prefixBytes)
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes), prefix, nil
+}
+
+// HashAPIKey => Yang disimpan di database hanya hash SHA-256
+func (a *apiKeyService) HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate => Cek key masih aktif lalu catat waktu terakhir dipakai
+func (a *apiKeyService) Authenticate(key string) (model.APIKey, error) {
+	// Prefix 12 karakter hex, secret base64url bisa mengandung "_"
+	if !strings.HasPrefix(key, apiKeyPrefix) || len(key) < len(apiKeyPrefix)+13 || key[len(apiKeyPrefix)+12] != '_' {
+		return model.APIKey{}, fmt.Errorf("invalid api key")
+	}
+	prefix := key[:len(apiKeyPrefix)+12]
+
+	apiKey, err := a.repo.GetAPIKeyByPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return model.APIKey{}, fmt.Errorf("invalid api key")
+		}
+		return model.APIKey{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(a.HashAPIKey(key))) != 1 {
+		return model.APIKey{}, fmt.Errorf("invalid api key")
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt)) || apiKey.User == nil {
+		return model.APIKey{}, fmt.Errorf("invalid api key")
+	}
+
+	err = a.repo.TouchAPIKey(apiKey.ID, now)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	return apiKey, nil
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"edu-learn/model"
+	"edu-learn/repository"
+	"edu-learn/utils/apperror"
+	"testing"
+	"time"
+)
+
+type fakeAPIKeyRepository struct {
+	repository.APIKeyRepository
+	key     model.APIKey
+	touched []int
+}
+
+func (f *fakeAPIKeyRepository) GetAPIKeyByPrefix(prefix string) (model.APIKey, error) {
+	if prefix != f.key.Prefix {
+		return model.APIKey{}, apperror.NotFound("api key not found")
+	}
+	return f.key, nil
+}
+
+func (f *fakeAPIKeyRepository) TouchAPIKey(id int, usedAt time.Time) error {
+	f.touched = append(f.touched, id)
+	return nil
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		modify func(key *model.APIKey)
+		rawKey func(rawKey string) string
+		wantOK bool
+	}{
+		{"active key", func(key *model.APIKey) {}, nil, true},
+		{"not yet expired", func(key *model.APIKey) { key.ExpiresAt = &future }, nil, true},
+		{"expired", func(key *model.APIKey) { key.ExpiresAt = &past }, nil, false},
+		{"revoked", func(key *model.APIKey) { key.RevokedAt = &past }, nil, false},
+		{"owner deleted", func(key *model.APIKey) { key.User = nil }, nil, false},
+		{"wrong secret", func(key *model.APIKey) {}, func(rawKey string) string { return rawKey + "x" }, false},
+		{"unknown prefix", func(key *model.APIKey) {}, func(rawKey string) string { return "edu_000000000000" + rawKey[16:] }, false},
+		{"malformed", func(key *model.APIKey) {}, func(rawKey string) string { return "Bearer " + rawKey }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAPIKeyRepository{}
+			apiKeys := NewAPIKeyService(repo)
+
+			rawKey, prefix, err := apiKeys.GenerateAPIKey()
+			if err != nil {
+				t.Fatalf("GenerateAPIKey: %v", err)
+			}
+			repo.key = model.APIKey{ID: 1, UserID: 7, User: &model.User{ID: 7, Role: "instructor"}, Prefix: prefix, KeyHash: apiKeys.HashAPIKey(rawKey)}
+			tt.modify(&repo.key)
+			if tt.rawKey != nil {
+				rawKey = tt.rawKey(rawKey)
+			}
+
+			_, err = apiKeys.Authenticate(rawKey)
+			if ok := err == nil; ok != tt.wantOK {
+				t.Fatalf("Authenticate error = %v, want ok %v", err, tt.wantOK)
+			}
+			if touched := len(repo.touched) == 1; touched != tt.wantOK {
+				t.Errorf("last used updated = %v, want %v", touched, tt.wantOK)
+			}
+		})
+	}
+}
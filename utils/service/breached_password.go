@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Panjang awalan hash SHA-1 yang dipakai untuk bucket k-anonymity, sama dengan range API HIBP
+const breachedPrefixLength = 5
+
+// maxBreachedLineBytes => Baris "HASH:jumlah" di file HIBP jauh lebih pendek dari ini
+const maxBreachedLineBytes = 128
+
+// breachedPasswordList => Daftar hash password bocor yang dibaca dari disk saat dicek, tidak dimuat ke memori
+type breachedPasswordList interface {
+	Contains(hash string) (bool, error)
+}
+
+// breachedRangeDir => Direktori hasil unduhan range API HIBP, satu file per awalan 5 karakter
+// (misalnya 21BD1) berisi baris "SUFFIX:jumlah" dengan suffix 35 karakter
+type breachedRangeDir struct {
+	path string
+}
+
+func (d breachedRangeDir) Contains(hash string) (bool, error) {
+	prefix, suffix := hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+
+	file, err := os.Open(filepath.Join(d.path, prefix))
+	if errors.Is(err, fs.ErrNotExist) {
+		// Unduhan lengkap selalu berisi semua awalan, bucket yang tidak ada berarti tidak ada hash bocor
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open breached password range: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineHash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(lineHash, suffix) || strings.EqualFold(lineHash, hash) {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read breached password range: %w", err)
+	}
+
+	return false, nil
+}
+
+// breachedSortedFile => File unduhan HIBP "ordered by hash", satu "HASH:jumlah" per baris urut naik.
+// Hash dicari dengan binary search langsung di file sehingga memori tetap kecil berapa pun ukurannya
+type breachedSortedFile struct {
+	file *os.File
+	size int64
+}
+
+func (s *breachedSortedFile) Contains(hash string) (bool, error) {
+	// Cari offset terkecil yang baris pertamanya (mulai di atau setelah offset) >= hash
+	lo, hi := int64(0), s.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		lineHash, err := s.hashAt(mid)
+		if err != nil {
+			return false, err
+		}
+
+		if lineHash != "" && lineHash < hash {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	lineHash, err := s.hashAt(lo)
+	if err != nil {
+		return false, err
+	}
+
+	return lineHash == hash, nil
+}
+
+// hashAt => Hash di baris pertama yang dimulai tepat di offset atau sesudahnya, kosong jika sudah akhir file
+func (s *breachedSortedFile) hashAt(offset int64) (string, error) {
+	if offset > 0 {
+		// Mundur satu byte supaya baris yang dimulai tepat di offset tidak terlewat
+		buf := make([]byte, maxBreachedLineBytes)
+		n, err := s.file.ReadAt(buf, offset-1)
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read breached password file: %w", err)
+		}
+
+		newline := bytes.IndexByte(buf[:n], '\n')
+		if newline < 0 {
+			return "", nil
+		}
+		offset += int64(newline)
+	}
+
+	if offset >= s.size {
+		return "", nil
+	}
+
+	buf := make([]byte, maxBreachedLineBytes)
+	n, err := s.file.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read breached password file: %w", err)
+	}
+
+	line, _, _ := bytes.Cut(buf[:n], []byte("\n"))
+	lineHash, _, _ := strings.Cut(strings.TrimSpace(string(line)), ":")
+	return strings.ToUpper(lineHash), nil
+}
+
+// openBreachedPasswords => PASSWORD_BREACHED_FILE bisa berupa direktori range HIBP atau file hash yang sudah diurutkan
+func openBreachedPasswords(path string) (breachedPasswordList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password file: %v", err)
+	}
+
+	if info.IsDir() {
+		return breachedRangeDir{path: path}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password file: %v", err)
+	}
+
+	list := &breachedSortedFile{file: file, size: info.Size()}
+
+	// Baris pertama dicek supaya file dengan format lain ketahuan saat start, bukan diam-diam tidak pernah cocok
+	first, err := list.hashAt(0)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := hex.DecodeString(first); err != nil || len(first) != sha1.Size*2 {
+		file.Close()
+		return nil, fmt.Errorf("invalid breached password file: expected sorted SHA-1 hashes, got %q", first)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(text string) string {
+	sum := sha1.Sum([]byte(text))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func breachedHashes(count int) []string {
+	hashes := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		hashes = append(hashes, sha1Hex(fmt.Sprintf("leaked-%d", i)))
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+func TestBreachedSortedFile(t *testing.T) {
+	hashes := breachedHashes(500)
+
+	var content strings.Builder
+	for i, hash := range hashes {
+		// Jumlah kemunculan dibuat beda panjang supaya baris tidak sama lebar
+		fmt.Fprintf(&content, "%s:%d\r\n", hash, i*37)
+	}
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	if err := os.WriteFile(path, []byte(content.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := openBreachedPasswords(path)
+	if err != nil {
+		t.Fatalf("openBreachedPasswords: %v", err)
+	}
+
+	for _, hash := range hashes {
+		found, err := list.Contains(hash)
+		if err != nil || !found {
+			t.Fatalf("Contains(%s) = %v, %v, want true", hash, found, err)
+		}
+	}
+
+	for i := 0; i < 500; i++ {
+		hash := sha1Hex(fmt.Sprintf("safe-%d", i))
+		found, err := list.Contains(hash)
+		if err != nil || found {
+			t.Fatalf("Contains(%s) = %v, %v, want false", hash, found, err)
+		}
+	}
+
+	// Di luar rentang file, sebelum hash pertama dan setelah hash terakhir
+	for _, hash := range []string{strings.Repeat("0", 40), strings.Repeat("F", 40)} {
+		if found, err := list.Contains(hash); err != nil || found {
+			t.Errorf("Contains(%s) = %v, %v, want false", hash, found, err)
+		}
+	}
+}
+
+func TestBreachedRangeDir(t *testing.T) {
+	dir := t.TempDir()
+	hashes := breachedHashes(200)
+
+	buckets := map[string][]string{}
+	for _, hash := range hashes {
+		prefix := hash[:breachedPrefixLength]
+		buckets[prefix] = append(buckets[prefix], hash[breachedPrefixLength:]+":3")
+	}
+	for prefix, lines := range buckets {
+		if err := os.WriteFile(filepath.Join(dir, prefix), []byte(strings.Join(lines, "\r\n")), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := openBreachedPasswords(dir)
+	if err != nil {
+		t.Fatalf("openBreachedPasswords: %v", err)
+	}
+
+	for _, hash := range hashes {
+		if found, err := list.Contains(hash); err != nil || !found {
+			t.Fatalf("Contains(%s) = %v, %v, want true", hash, found, err)
+		}
+	}
+
+	// Awalan sama dengan hash bocor tapi suffix beda, dan awalan yang tidak punya file
+	sameBucket := hashes[0][:breachedPrefixLength] + strings.Repeat("0", 35)
+	for _, hash := range []string{sameBucket, sha1Hex("safe-password")} {
+		if found, err := list.Contains(hash); err != nil || found {
+			t.Errorf("Contains(%s) = %v, %v, want false", hash, found, err)
+		}
+	}
+}
+
+func TestOpenBreachedPasswordsRejectsInvalidFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty file", ""},
+		{"plain passwords", "password123\nqwerty\n"},
+		{"suffix only", strings.Repeat("A", 35) + ":10\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "breached.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := openBreachedPasswords(path); err == nil {
+				t.Errorf("openBreachedPasswords accepted %q", tt.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignEmailVerificationToken => Token berformat userID.expiry.signature, email ikut ditandatangani
+// supaya link lama tidak berlaku setelah email diganti
+func SignEmailVerificationToken(secret []byte, userID int, email string, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d", userID, expiresAt.Unix())
+	return payload + "." + emailVerificationSignature(secret, payload, email)
+}
+
+// ParseEmailVerificationToken => Ambil user id dan waktu kedaluwarsa tanpa memeriksa signature
+func ParseEmailVerificationToken(token string) (int, time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, time.Time{}, fmt.Errorf("invalid verification token")
+	}
+
+	userID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid verification token")
+	}
+
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid verification token")
+	}
+
+	return userID, time.Unix(expiry, 0), nil
+}
+
+// VerifyEmailVerificationToken => Cocokkan signature dengan email user saat ini
+func VerifyEmailVerificationToken(secret []byte, token string, email string) bool {
+	index := strings.LastIndex(token, ".")
+	if len(secret) == 0 || index < 0 {
+		return false
+	}
+
+	expected := emailVerificationSignature(secret, token[:index], email)
+	return hmac.Equal([]byte(expected), []byte(token[index+1:]))
+}
+
+func emailVerificationSignature(secret []byte, payload string, email string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload + "." + strings.ToLower(email)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -1,10 +1,19 @@
 package service
 
 import (
-	"fmt"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
 	"edu-learn/config"
 	"edu-learn/model"
 	modelutils "edu-learn/utils/model_utils"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -15,23 +24,70 @@ type jwtService struct {
 }
 
 type JwtService interface {
-	CreateToken(user model.User) (string, error)
+	CreateToken(user model.User, sessionID string) (string, error)
 	VerifyToken(tokenString string) (modelutils.JWTPayloadClaim, error)
+	CreateMFAToken(user model.User) (string, error)
+	CreateImpersonationToken(user model.User, actor model.User) (string, time.Time, error)
+	VerifyMFAToken(tokenString string) (modelutils.JWTPayloadClaim, error)
+	CreateRefreshToken() (string, time.Time, error)
+	HashRefreshToken(token string) string
+	JWKS() modelutils.JWKS
+}
+
+func (j *jwtService) CreateToken(user model.User, sessionID string) (string, error) {
+	return j.signToken(user, j.cfg.AccessTokenLifeTime, sessionID, false, nil)
+}
+
+// CreateMFAToken => Token berumur pendek yang hanya bisa ditukar dengan token asli setelah kode MFA benar
+func (j *jwtService) CreateMFAToken(user model.User) (string, error) {
+	return j.signToken(user, j.cfg.MFATokenLifeTime, "", true, nil)
 }
 
-func (j *jwtService) CreateToken(user model.User) (string, error) {
-	tokenKey := j.cfg.JWTSignatureKey
+// CreateImpersonationToken => Token atas nama user dengan claim act berisi admin yang meminta
+func (j *jwtService) CreateImpersonationToken(user model.User, actor model.User) (string, time.Time, error) {
+	token, err := j.signToken(user, j.cfg.ImpersonationTokenLifeTime, "", false, &modelutils.ActClaim{
+		Subject: strconv.Itoa(actor.ID),
+		UserId:  actor.ID,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, time.Now().Add(j.cfg.ImpersonationTokenLifeTime), nil
+}
+
+func (j *jwtService) signToken(user model.User, lifeTime time.Duration, sessionID string, mfaPending bool, act *modelutils.ActClaim) (string, error) {
+	var tokenKey interface{} = j.cfg.JWTSignatureKey
+	if j.cfg.JWTPrivateKey != nil {
+		tokenKey = j.cfg.JWTPrivateKey
+	}
+
+	// jti dipakai untuk mencabut token sebelum kedaluwarsa
+	jti := make([]byte, 16)
+	_, err := rand.Read(jti)
+	if err != nil {
+		return "", err
+	}
 
+	now := time.Now()
 	claims := modelutils.JWTPayloadClaim{
 		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer: j.cfg.ApplicationName,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.cfg.AccessTokenLifeTime)),
+			ID:        hex.EncodeToString(jti),
+			Issuer:    j.cfg.ApplicationName,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(lifeTime)),
 		},
-		UserId: user.ID,
-		Role:   user.Role,
+		UserId:     user.ID,
+		Role:       user.Role,
+		SessionID:  sessionID,
+		MFAPending: mfaPending,
+		Act:        act,
 	}
 
 	jwtNewClaim := jwt.NewWithClaims(j.cfg.JWTSigningMethod, claims)
+	if j.cfg.JWTKeyID != "" {
+		jwtNewClaim.Header["kid"] = j.cfg.JWTKeyID
+	}
 
 	token, err := jwtNewClaim.SignedString(tokenKey)
 	if err != nil {
@@ -42,10 +98,50 @@ func (j *jwtService) CreateToken(user model.User) (string, error) {
 }
 
 func (j *jwtService) VerifyToken(tokenString string) (modelutils.JWTPayloadClaim, error) {
+	claim, err := j.parseToken(tokenString)
+	if err != nil {
+		return modelutils.JWTPayloadClaim{}, err
+	}
+
+	// Token MFA tidak boleh dipakai untuk mengakses API
+	if claim.MFAPending {
+		return modelutils.JWTPayloadClaim{}, fmt.Errorf("mfa verification required")
+	}
+
+	return claim, nil
+}
+
+func (j *jwtService) VerifyMFAToken(tokenString string) (modelutils.JWTPayloadClaim, error) {
+	claim, err := j.parseToken(tokenString)
+	if err != nil {
+		return modelutils.JWTPayloadClaim{}, err
+	}
+
+	if !claim.MFAPending {
+		return modelutils.JWTPayloadClaim{}, fmt.Errorf("not an mfa token")
+	}
+
+	return claim, nil
+}
+
+func (j *jwtService) parseToken(tokenString string) (modelutils.JWTPayloadClaim, error) {
 	tokenParse, err := jwt.ParseWithClaims(tokenString, &modelutils.JWTPayloadClaim{},
 		func(t *jwt.Token) (interface{}, error) {
-			return j.cfg.JWTSignatureKey, nil
+			if j.cfg.JWTVerificationKeys == nil {
+				return j.cfg.JWTSignatureKey, nil
+			}
+
+			// Pilih public key berdasarkan header kid
+			kid, _ := t.Header["kid"].(string)
+			key, ok := j.cfg.JWTVerificationKeys[kid]
+			if !ok {
+				return nil, fmt.Errorf("unknown key id: %s", kid)
+			}
+			return key, nil
 		},
+		jwt.WithValidMethods([]string{j.cfg.JWTSigningMethod.Alg()}),
+		jwt.WithIssuer(j.cfg.ApplicationName),
+		jwt.WithExpirationRequired(),
 	)
 	if err != nil {
 		return modelutils.JWTPayloadClaim{}, err
@@ -59,6 +155,55 @@ func (j *jwtService) VerifyToken(tokenString string) (modelutils.JWTPayloadClaim
 	return *claim, nil
 }
 
+// CreateRefreshToken => Refresh token berupa string acak, bukan JWT, supaya bisa dicabut di server
+func (j *jwtService) CreateRefreshToken() (string, time.Time, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), time.Now().Add(j.cfg.RefreshTokenLifeTime), nil
+}
+
+// HashRefreshToken => Yang disimpan di database hanya hash SHA-256
+func (j *jwtService) HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// JWKS => Public key untuk verifikasi token oleh service lain, kosong jika memakai HS256
+func (j *jwtService) JWKS() modelutils.JWKS {
+	jwks := modelutils.JWKS{Keys: []modelutils.JWK{}}
+
+	for kid, key := range j.cfg.JWTVerificationKeys {
+		switch publicKey := key.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, modelutils.JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: jwt.SigningMethodRS256.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, modelutils.JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: jwt.SigningMethodEdDSA.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+
+	sort.Slice(jwks.Keys, func(a, b int) bool { return jwks.Keys[a].Kid < jwks.Keys[b].Kid })
+
+	return jwks
+}
+
 func NewJwtService(cfg config.TokenConfig) JwtService {
 	return &jwtService{cfg: cfg}
 }
\ No newline at end of file
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"edu-learn/config"
+	modelutils "edu-learn/utils/model_utils"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MailSender interface {
+	Send(message modelutils.MailMessage) error
+}
+
+// Sender untuk development: hanya penerima dan subjek yang ditulis ke log aplikasi.
+// Isi email berisi token reset password dan verifikasi, jadi tidak ikut dicatat (pakai MAIL_SENDER=file untuk membacanya)
+type logMailSender struct{}
+
+func (l *logMailSender) Send(message modelutils.MailMessage) error {
+	log.Printf("[mail] to=%s subject=%q body=<redacted %d bytes>", message.To, message.Subject, len(message.Body))
+	return nil
+}
+
+func NewLogMailSender() MailSender {
+	return &logMailSender{}
+}
+
+// Sender untuk development dan testing: email ditambahkan ke file
+type fileMailSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (f *fileMailSender) Send(message modelutils.MailMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "Date: %s\nFrom: %s\nTo: %s\nSubject: %s\n\n%s\n\n---\n",
+		time.Now().Format(time.RFC1123Z), message.From, message.To, message.Subject, message.Body)
+	if err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+
+	return nil
+}
+
+func NewFileMailSender(path string) MailSender {
+	return &fileMailSender{path: path}
+}
+
+type smtpMailSender struct {
+	cfg config.MailConfig
+}
+
+func (s *smtpMailSender) Send(message modelutils.MailMessage) error {
+	// Cegah header injection dari input user
+	if strings.ContainsAny(message.To+message.Subject, "\r\n") {
+		return fmt.Errorf("invalid mail header")
+	}
+
+	body := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		message.From, message.To, message.Subject, message.Body)
+
+	var auth smtp.Auth
+	if s.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", s.cfg.SMTPUsername, s.cfg.SMTPPassword, s.cfg.SMTPHost)
+	}
+
+	return smtp.SendMail(s.cfg.SMTPHost+":"+s.cfg.SMTPPort, auth, message.From, []string{message.To}, []byte(body))
+}
+
+func NewSmtpMailSender(cfg config.MailConfig) MailSender {
+	return &smtpMailSender{cfg: cfg}
+}
+
+// NewMailSender => Pilih sender sesuai MAIL_SENDER
+func NewMailSender(cfg config.MailConfig) (MailSender, error) {
+	switch cfg.MailSender {
+	case "log":
+		return NewLogMailSender(), nil
+	case "file":
+		return NewFileMailSender(cfg.MailFilePath), nil
+	case "smtp":
+		return NewSmtpMailSender(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown mail sender: %s", cfg.MailSender)
+	}
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"edu-learn/config"
+	modelutils "edu-learn/utils/model_utils"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type OIDCProvider interface {
+	Name() string
+	AuthCodeURL(state string, nonce string, codeChallenge string) (string, error)
+	Exchange(code string, codeVerifier string) (modelutils.OIDCIdentity, error)
+}
+
+// NewPKCEVerifier => code_verifier acak 43 karakter (RFC 7636)
+func NewPKCEVerifier() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// PKCEChallenge => code_challenge dengan metode S256
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func authCodeParams(clientID string, redirectURL string, scopes string, state string, nonce string, codeChallenge string) url.Values {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", clientID)
+	params.Set("redirect_uri", redirectURL)
+	params.Set("scope", scopes)
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+	return params
+}
+
+// Provider OIDC standar (Google, Keycloak, dan lainnya), endpoint dibaca dari discovery document
+type oidcProvider struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey // kid => public key dari jwks_uri
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"` // sebagian provider mengirim "true" sebagai string
+	Name          string      `json:"name"`
+	Nonce         string      `json:"nonce"`
+}
+
+func (o *oidcProvider) Name() string {
+	return o.cfg.OIDCName
+}
+
+func (o *oidcProvider) AuthCodeURL(state string, nonce string, codeChallenge string) (string, error) {
+	discovery, err := o.discover()
+	if err != nil {
+		return "", err
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	params := authCodeParams(o.cfg.OIDCClientID, o.cfg.OIDCRedirectURL, o.cfg.OIDCScopes, state, nonce, codeChallenge)
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+func (o *oidcProvider) Exchange(code string, codeVerifier string) (modelutils.OIDCIdentity, error) {
+	discovery, err := o.discover()
+	if err != nil {
+		return modelutils.OIDCIdentity{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", o.cfg.OIDCRedirectURL)
+	form.Set("client_id", o.cfg.OIDCClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return modelutils.OIDCIdentity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if o.cfg.OIDCClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.cfg.OIDCClientID), url.QueryEscape(o.cfg.OIDCClientSecret))
+	}
+
+	var result struct {
+		IDToken string `json:"id_token"`
+	}
+	err = doGatewayRequest(o.client, req, &result)
+	if err != nil {
+		return modelutils.OIDCIdentity{}, fmt.Errorf("oidc: %w", err)
+	}
+	if result.IDToken == "" {
+		return modelutils.OIDCIdentity{}, fmt.Errorf("oidc: token response has no id_token")
+	}
+
+	return o.verifyIDToken(result.IDToken, discovery.Issuer)
+}
+
+// verifyIDToken => Cek tanda tangan, issuer, audience, dan masa berlaku ID token
+func (o *oidcProvider) verifyIDToken(idToken string, issuer string) (modelutils.OIDCIdentity, error) {
+	tokenParse, err := jwt.ParseWithClaims(idToken, &idTokenClaims{},
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return o.publicKey(kid)
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(o.cfg.OIDCClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return modelutils.OIDCIdentity{}, fmt.Errorf("oidc: invalid id token: %w", err)
+	}
+
+	claims, ok := tokenParse.Claims.(*idTokenClaims)
+	if !ok || claims.Subject == "" {
+		return modelutils.OIDCIdentity{}, fmt.Errorf("oidc: invalid id token")
+	}
+
+	emailVerified := false
+	switch value := claims.EmailVerified.(type) {
+	case bool:
+		emailVerified = value
+	case string:
+		emailVerified = value == "true"
+	}
+
+	return modelutils.OIDCIdentity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: emailVerified,
+		Name:          claims.Name,
+		Nonce:         claims.Nonce,
+	}, nil
+}
+
+func (o *oidcProvider) discover() (oidcDiscovery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.discovery != nil {
+		return *o.discovery, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, o.cfg.OIDCIssuerURL+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return oidcDiscovery{}, err
+	}
+
+	var discovery oidcDiscovery
+	err = doGatewayRequest(o.client, req, &discovery)
+	if err != nil {
+		return oidcDiscovery{}, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return oidcDiscovery{}, fmt.Errorf("oidc discovery: incomplete provider metadata")
+	}
+
+	o.discovery = &discovery
+	return discovery, nil
+}
+
+// publicKey => JWKS dibaca ulang jika kid belum dikenal, misalnya setelah provider rotasi key
+func (o *oidcProvider) publicKey(kid string) (*rsa.PublicKey, error) {
+	discovery, err := o.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if key, ok := o.keys[kid]; ok {
+		return key, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, discovery.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks modelutils.JWKS
+	err = doGatewayRequest(o.client, req, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("oidc jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			continue
+		}
+
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	o.keys = keys
+
+	key, ok := o.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+	return key, nil
+}
+
+func NewOIDCProvider(cfg config.OIDCConfig) OIDCProvider {
+	return &oidcProvider{cfg: cfg, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Stub provider untuk development dan testing, halaman login provider diganti endpoint lokal
+// yang langsung menerbitkan code untuk email yang diberikan
+type StubOIDCProvider struct {
+	authorizeURL string
+	redirectURL  string
+
+	mu    sync.Mutex
+	codes map[string]stubAuthorization
+}
+
+type stubAuthorization struct {
+	identity      modelutils.OIDCIdentity
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+func (s *StubOIDCProvider) Name() string {
+	return "stub"
+}
+
+func (s *StubOIDCProvider) AuthCodeURL(state string, nonce string, codeChallenge string) (string, error) {
+	params := authCodeParams("stub", s.redirectURL, "openid email profile", state, nonce, codeChallenge)
+	return s.authorizeURL + "?" + params.Encode(), nil
+}
+
+// Authorize => Pengganti halaman login provider, mengembalikan URL callback berisi code dan state
+func (s *StubOIDCProvider) Authorize(email string, name string, emailVerified bool, state string, nonce string, codeChallenge string) (string, error) {
+	if email == "" || state == "" || codeChallenge == "" {
+		return "", fmt.Errorf("email, state and code_challenge are required")
+	}
+
+	code, err := NewPKCEVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	for key, authorization := range s.codes {
+		if now.After(authorization.expiresAt) {
+			delete(s.codes, key)
+		}
+	}
+	s.codes[code] = stubAuthorization{
+		identity: modelutils.OIDCIdentity{
+			Subject:       "stub|" + strings.ToLower(email),
+			Email:         email,
+			EmailVerified: emailVerified,
+			Name:          name,
+			Nonce:         nonce,
+		},
+		codeChallenge: codeChallenge,
+		expiresAt:     now.Add(5 * time.Minute),
+	}
+	s.mu.Unlock()
+
+	params := url.Values{}
+	params.Set("code", code)
+	params.Set("state", state)
+	return s.redirectURL + "?" + params.Encode(), nil
+}
+
+// Exchange => Code hanya bisa dipakai sekali dan code_verifier harus cocok dengan code_challenge
+func (s *StubOIDCProvider) Exchange(code string, codeVerifier string) (modelutils.OIDCIdentity, error) {
+	s.mu.Lock()
+	authorization, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+
+	if !ok || time.Now().After(authorization.expiresAt) {
+		return modelutils.OIDCIdentity{}, fmt.Errorf("oidc: invalid authorization code")
+	}
+	if subtle.ConstantTimeCompare([]byte(PKCEChallenge(codeVerifier)), []byte(authorization.codeChallenge)) != 1 {
+		return modelutils.OIDCIdentity{}, fmt.Errorf("oidc: invalid code verifier")
+	}
+
+	return authorization.identity, nil
+}
+
+func NewStubOIDCProvider(authorizeURL string, redirectURL string) *StubOIDCProvider {
+	return &StubOIDCProvider{authorizeURL: authorizeURL, redirectURL: redirectURL, codes: make(map[string]stubAuthorization)}
+}
+
+// NewOIDCLoginProvider => Pilih provider sesuai OIDC_PROVIDER, nil jika login OIDC tidak dipakai
+func NewOIDCLoginProvider(cfg config.Config) (OIDCProvider, error) {
+	switch cfg.OIDCProvider {
+	case "":
+		return nil, nil
+	case "stub":
+		if !cfg.OIDCStubEnabled {
+			return nil, fmt.Errorf("stub oidc provider is not enabled")
+		}
+		return NewStubOIDCProvider(cfg.AppBaseURL+"/api/oidc/stub/authorize", cfg.OIDCRedirectURL), nil
+	case "oidc":
+		return NewOIDCProvider(cfg.OIDCConfig), nil
+	default:
+		return nil, fmt.Errorf("unknown oidc provider: %s", cfg.OIDCProvider)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"crypto/sha1"
+	"edu-learn/config"
+	"edu-learn/utils/apperror"
+	"edu-learn/utils/validation"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxPasswordBytes => bcrypt hanya memakai 72 byte pertama
+const maxPasswordBytes = 72
+
+type passwordService struct {
+	cfg      config.PasswordConfig
+	breached breachedPasswordList // nil => cek password bocor nonaktif
+}
+
+type PasswordService interface {
+	Validate(password string, email string) error
+	Hash(password string) (string, error)
+	Compare(hashedPassword string, password string) error
+	NeedsRehash(hashedPassword string) bool
+}
+
+// Validate => Cek panjang, jenis karakter, email, dan daftar password bocor
+func (p *passwordService) Validate(password string, email string) error {
+	if len([]rune(password)) < p.cfg.PasswordMinLength {
+		return passwordPolicyError("too_short", fmt.Sprintf("password must be at least %d characters", p.cfg.PasswordMinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		return passwordPolicyError("too_long", fmt.Sprintf("password must be at most %d bytes", maxPasswordBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.cfg.PasswordRequireUpper && !hasUpper {
+		return passwordPolicyError("missing_uppercase", "password must contain an uppercase letter")
+	}
+	if p.cfg.PasswordRequireLower && !hasLower {
+		return passwordPolicyError("missing_lowercase", "password must contain a lowercase letter")
+	}
+	if p.cfg.PasswordRequireDigit && !hasDigit {
+		return passwordPolicyError("missing_digit", "password must contain a digit")
+	}
+	if p.cfg.PasswordRequireSymbol && !hasSymbol {
+		return passwordPolicyError("missing_symbol", "password must contain a symbol")
+	}
+
+	// Bagian sebelum @ yang terlalu pendek diabaikan supaya tidak melarang huruf biasa
+	lowerPassword := strings.ToLower(password)
+	localPart, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	if len(localPart) >= 3 && strings.Contains(lowerPassword, localPart) {
+		return passwordPolicyError("contains_email", "password must not contain your email")
+	}
+
+	breached, err := p.isBreached(password)
+	if err != nil {
+		return err
+	}
+	if breached {
+		return passwordPolicyError("breached", "password has appeared in a data breach, choose another one")
+	}
+
+	return nil
+}
+
+// passwordPolicyError => Password yang ditolak dibalas 422 seperti error validasi field lainnya
+func passwordPolicyError(code string, message string) error {
+	return apperror.InvalidFields([]validation.FieldError{{Field: "password", Code: code, Message: message}})
+}
+
+func (p *passwordService) Hash(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), p.cfg.BcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password")
+	}
+
+	return string(hashedPassword), nil
+}
+
+func (p *passwordService) Compare(hashedPassword string, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+}
+
+// NeedsRehash => Hash dibuat dengan cost lain dari BCRYPT_COST saat ini
+func (p *passwordService) NeedsRehash(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err == nil && cost != p.cfg.BcryptCost
+}
+
+// isBreached => Hanya bucket dengan awalan hash yang sama yang dibaca, password tidak pernah dikirim ke luar
+func (p *passwordService) isBreached(password string) (bool, error) {
+	if p.breached == nil {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	return p.breached.Contains(hash)
+}
+
+func NewPasswordService(cfg config.PasswordConfig) (PasswordService, error) {
+	service := &passwordService{cfg: cfg}
+
+	if cfg.PasswordBreachedFile != "" {
+		breached, err := openBreachedPasswords(cfg.PasswordBreachedFile)
+		if err != nil {
+			return nil, err
+		}
+		service.breached = breached
+	}
+
+	return service, nil
+}
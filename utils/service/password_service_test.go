@@ -0,0 +1,69 @@
+package service
+
+import (
+	"edu-learn/config"
+	"edu-learn/utils/apperror"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPasswordServiceValidate(t *testing.T) {
+	dir := t.TempDir()
+	breached := sha1Hex("Password1!")
+	err := os.WriteFile(filepath.Join(dir, breached[:breachedPrefixLength]), []byte(breached[breachedPrefixLength:]+":42\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passwords, err := NewPasswordService(config.PasswordConfig{
+		PasswordMinLength:     8,
+		PasswordRequireUpper:  true,
+		PasswordRequireLower:  true,
+		PasswordRequireDigit:  true,
+		PasswordRequireSymbol: true,
+		PasswordBreachedFile:  dir,
+		BcryptCost:            4,
+	})
+	if err != nil {
+		t.Fatalf("NewPasswordService: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		wantCode string
+	}{
+		{"valid", "Kopi-Susu-88", ""},
+		{"too short", "Ab1!", "too_short"},
+		{"too long", "Aa1!" + strings.Repeat("a", 70), "too_long"},
+		{"missing uppercase", "kopi-susu-88", "missing_uppercase"},
+		{"missing lowercase", "KOPI-SUSU-88", "missing_lowercase"},
+		{"missing digit", "Kopi-Susu-Ok", "missing_digit"},
+		{"missing symbol", "KopiSusu88", "missing_symbol"},
+		{"contains email", "Budi-Santoso-1", "contains_email"},
+		{"breached", "Password1!", "breached"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := passwords.Validate(tt.password, "budi@example.com")
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || !errors.Is(err, apperror.ErrValidation) {
+				t.Fatalf("Validate() = %v, want validation error", err)
+			}
+			if len(appErr.Fields) != 1 || appErr.Fields[0].Field != "password" || appErr.Fields[0].Code != tt.wantCode {
+				t.Errorf("fields = %+v, want password/%s", appErr.Fields, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"edu-learn/config"
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type PaymentGateway interface {
+	Name() string
+	CreateCharge(payment model.Payment) (modelutils.PaymentCharge, error)
+	Refund(payment model.Payment, amount float64, reason string) error
+	// ParseWebhook => Verifikasi signature notifikasi sesuai format gateway lalu petakan ke status pembayaran
+	ParseWebhook(header http.Header, body []byte) (modelutils.PaymentWebhookEvent, error)
+}
+
+// orderID => ID transaksi yang dikirim ke payment gateway
+func orderID(payment model.Payment) string {
+	return fmt.Sprintf("EDU-%d", payment.ID)
+}
+
+// Fake gateway untuk development dan testing, tidak memanggil layanan luar.
+// Charge tetap pending sampai ada webhook (misalnya dari FakeWebhookSender), status lain hanya untuk test
+type fakePaymentGateway struct {
+	status        string
+	webhookSecret []byte
+}
+
+func (f *fakePaymentGateway) Name() string {
+	return "fake"
+}
+
+func (f *fakePaymentGateway) CreateCharge(payment model.Payment) (modelutils.PaymentCharge, error) {
+	return modelutils.PaymentCharge{
+		Reference: orderID(payment),
+		Status:    f.status,
+	}, nil
+}
+
+func (f *fakePaymentGateway) Refund(payment model.Payment, amount float64, reason string) error {
+	return nil
+}
+
+// ParseWebhook => Format FakeWebhookSender: header X-Signature dan body {event_id, reference, status}
+func (f *fakePaymentGateway) ParseWebhook(header http.Header, body []byte) (modelutils.PaymentWebhookEvent, error) {
+	if !VerifyWebhookSignature(f.webhookSecret, body, header.Get(WebhookSignatureHeader)) {
+		return modelutils.PaymentWebhookEvent{}, apperror.Unauthorized("invalid signature")
+	}
+
+	var notification struct {
+		EventID   string `json:"event_id"`
+		Reference string `json:"reference"`
+		Status    string `json:"status"`
+	}
+	err := json.Unmarshal(body, &notification)
+	if err != nil || notification.EventID == "" || notification.Reference == "" {
+		return modelutils.PaymentWebhookEvent{}, apperror.BadRequest("invalid webhook payload")
+	}
+
+	return modelutils.PaymentWebhookEvent{EventID: notification.EventID, Reference: notification.Reference, Status: notification.Status}, nil
+}
+
+func NewFakePaymentGateway(status string, webhookSecret []byte) PaymentGateway {
+	return &fakePaymentGateway{status: status, webhookSecret: webhookSecret}
+}
+
+// Midtrans Snap
+type midtransPaymentGateway struct {
+	cfg    config.PaymentConfig
+	client *http.Client
+}
+
+func (m *midtransPaymentGateway) Name() string {
+	return "midtrans"
+}
+
+func (m *midtransPaymentGateway) CreateCharge(payment model.Payment) (modelutils.PaymentCharge, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"transaction_details": map[string]interface{}{
+			"order_id":     orderID(payment),
+			"gross_amount": int64(payment.Amount),
+		},
+	})
+	if err != nil {
+		return modelutils.PaymentCharge{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, m.cfg.BaseURL+"/snap/v1/transactions", bytes.NewReader(body))
+	if err != nil {
+		return modelutils.PaymentCharge{}, err
+	}
+	req.SetBasicAuth(m.cfg.ServerKey, "")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	var result struct {
+		Token       string `json:"token"`
+		RedirectURL string `json:"redirect_url"`
+	}
+	err = doGatewayRequest(m.client, req, &result)
+	if err != nil {
+		return modelutils.PaymentCharge{}, fmt.Errorf("midtrans: %w", err)
+	}
+
+	return modelutils.PaymentCharge{
+		Reference:  orderID(payment),
+		PaymentURL: result.RedirectURL,
+		Status:     model.PaymentStatusPending,
+	}, nil
+}
+
+func (m *midtransPaymentGateway) Refund(payment model.Payment, amount float64, reason string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"refund_key": fmt.Sprintf("%s-%d", payment.GatewayRef, time.Now().UnixNano()),
+		"amount":     int64(amount),
+		"reason":     reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Refund memakai Core API (api.*), bukan Snap (app.*)
+	apiURL := strings.Replace(m.cfg.BaseURL, "://app.", "://api.", 1)
+	req, err := http.NewRequest(http.MethodPost, apiURL+"/v2/"+payment.GatewayRef+"/refund", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(m.cfg.ServerKey, "")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	var result map[string]interface{}
+	err = doGatewayRequest(m.client, req, &result)
+	if err != nil {
+		return fmt.Errorf("midtrans: %w", err)
+	}
+
+	return nil
+}
+
+// ParseWebhook => Notifikasi HTTP Midtrans, signature_key = SHA-512(order_id + status_code + gross_amount + server key)
+func (m *midtransPaymentGateway) ParseWebhook(header http.Header, body []byte) (modelutils.PaymentWebhookEvent, error) {
+	var notification struct {
+		OrderID           string `json:"order_id"`
+		TransactionID     string `json:"transaction_id"`
+		StatusCode        string `json:"status_code"`
+		GrossAmount       string `json:"gross_amount"`
+		SignatureKey      string `json:"signature_key"`
+		TransactionStatus string `json:"transaction_status"`
+		FraudStatus       string `json:"fraud_status"`
+	}
+	err := json.Unmarshal(body, &notification)
+	if err != nil {
+		return modelutils.PaymentWebhookEvent{}, apperror.BadRequest("invalid webhook payload")
+	}
+
+	sum := sha512.Sum512([]byte(notification.OrderID + notification.StatusCode + notification.GrossAmount + m.cfg.ServerKey))
+	if notification.SignatureKey == "" || !hmac.Equal([]byte(hex.EncodeToString(sum[:])), []byte(strings.ToLower(notification.SignatureKey))) {
+		return modelutils.PaymentWebhookEvent{}, apperror.Unauthorized("invalid signature")
+	}
+
+	if notification.OrderID == "" || notification.TransactionID == "" {
+		return modelutils.PaymentWebhookEvent{}, apperror.BadRequest("invalid webhook payload")
+	}
+
+	var status string
+	switch notification.TransactionStatus {
+	case "capture":
+		// Kartu kredit yang ditahan fraud detection masih menunggu keputusan
+		switch notification.FraudStatus {
+		case "", "accept":
+			status = model.PaymentStatusCompleted
+		case "challenge":
+			status = model.PaymentStatusPending
+		default:
+			status = model.PaymentStatusFailed
+		}
+	case "settlement":
+		status = model.PaymentStatusCompleted
+	case "pending":
+		status = model.PaymentStatusPending
+	case "deny", "cancel", "expire", "failure":
+		status = model.PaymentStatusFailed
+	case "refund":
+		status = model.PaymentStatusRefunded
+	default:
+		// partial_refund, authorize, chargeback, dan lainnya tidak mengubah status pembayaran
+		return modelutils.PaymentWebhookEvent{}, ErrWebhookIgnored
+	}
+
+	// Midtrans tidak punya id event, notifikasi ulang untuk status yang sama dianggap event yang sama
+	return modelutils.PaymentWebhookEvent{
+		EventID:   notification.TransactionID + ":" + notification.TransactionStatus + ":" + notification.FraudStatus,
+		Reference: notification.OrderID,
+		Status:    status,
+	}, nil
+}
+
+func NewMidtransPaymentGateway(cfg config.PaymentConfig) PaymentGateway {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://app.sandbox.midtrans.com"
+	}
+	return &midtransPaymentGateway{cfg: cfg, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Stripe Payment Intents
+type stripePaymentGateway struct {
+	cfg    config.PaymentConfig
+	client *http.Client
+}
+
+func (s *stripePaymentGateway) Name() string {
+	return "stripe"
+}
+
+func (s *stripePaymentGateway) CreateCharge(payment model.Payment) (modelutils.PaymentCharge, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(int64(math.Round(payment.Amount*100)), 10))
+	form.Set("currency", strings.ToLower(s.cfg.Currency))
+	form.Set("metadata[order_id]", orderID(payment))
+
+	req, err := http.NewRequest(http.MethodPost, s.cfg.BaseURL+"/v1/payment_intents", strings.NewReader(form.Encode()))
+	if err != nil {
+		return modelutils.PaymentCharge{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.cfg.ServerKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	err = doGatewayRequest(s.client, req, &result)
+	if err != nil {
+		return modelutils.PaymentCharge{}, fmt.Errorf("stripe: %w", err)
+	}
+
+	status := model.PaymentStatusPending
+	if result.Status == "succeeded" {
+		status = model.PaymentStatusCompleted
+	}
+
+	return modelutils.PaymentCharge{
+		Reference: result.ID,
+		Status:    status,
+	}, nil
+}
+
+func (s *stripePaymentGateway) Refund(payment model.Payment, amount float64, reason string) error {
+	form := url.Values{}
+	form.Set("payment_intent", payment.GatewayRef)
+	form.Set("amount", strconv.FormatInt(int64(math.Round(amount*100)), 10))
+	form.Set("metadata[reason]", reason)
+
+	req, err := http.NewRequest(http.MethodPost, s.cfg.BaseURL+"/v1/refunds", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.cfg.ServerKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result map[string]interface{}
+	err = doGatewayRequest(s.client, req, &result)
+	if err != nil {
+		return fmt.Errorf("stripe: %w", err)
+	}
+
+	return nil
+}
+
+// ParseWebhook => Event Stripe dengan header Stripe-Signature, reference pembayaran adalah id PaymentIntent
+func (s *stripePaymentGateway) ParseWebhook(header http.Header, body []byte) (modelutils.PaymentWebhookEvent, error) {
+	if !verifyStripeSignature(s.cfg.WebhookSecret, header.Get(StripeSignatureHeader), body, time.Now()) {
+		return modelutils.PaymentWebhookEvent{}, apperror.Unauthorized("invalid signature")
+	}
+
+	var event struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object struct {
+				ID            string `json:"id"`
+				PaymentIntent string `json:"payment_intent"`
+				PaymentStatus string `json:"payment_status"`
+				Refunded      bool   `json:"refunded"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	err := json.Unmarshal(body, &event)
+	if err != nil || event.ID == "" || event.Type == "" {
+		return modelutils.PaymentWebhookEvent{}, apperror.BadRequest("invalid webhook payload")
+	}
+
+	object := event.Data.Object
+	reference := object.PaymentIntent
+
+	var status string
+	switch event.Type {
+	case "payment_intent.succeeded":
+		reference, status = object.ID, model.PaymentStatusCompleted
+	case "payment_intent.canceled":
+		reference, status = object.ID, model.PaymentStatusFailed
+	case "checkout.session.completed":
+		// Metode pembayaran asinkron (transfer bank) baru lunas di async_payment_succeeded
+		if object.PaymentStatus != "paid" {
+			return modelutils.PaymentWebhookEvent{}, ErrWebhookIgnored
+		}
+		status = model.PaymentStatusCompleted
+	case "checkout.session.async_payment_succeeded":
+		status = model.PaymentStatusCompleted
+	case "checkout.session.async_payment_failed", "checkout.session.expired":
+		status = model.PaymentStatusFailed
+	case "charge.refunded":
+		// Refund sebagian tidak mengubah status pembayaran
+		if !object.Refunded {
+			return modelutils.PaymentWebhookEvent{}, ErrWebhookIgnored
+		}
+		status = model.PaymentStatusRefunded
+	default:
+		// payment_intent.payment_failed tidak dianggap gagal karena customer masih bisa mencoba lagi di intent yang sama
+		return modelutils.PaymentWebhookEvent{}, ErrWebhookIgnored
+	}
+
+	if reference == "" {
+		return modelutils.PaymentWebhookEvent{}, ErrWebhookIgnored
+	}
+
+	return modelutils.PaymentWebhookEvent{EventID: event.ID, Reference: reference, Status: status}, nil
+}
+
+func NewStripePaymentGateway(cfg config.PaymentConfig) PaymentGateway {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.stripe.com"
+	}
+	return &stripePaymentGateway{cfg: cfg, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func doGatewayRequest(client *http.Client, req *http.Request, result interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return json.Unmarshal(body, result)
+}
+
+// NewPaymentGateway => Pilih gateway sesuai PAYMENT_GATEWAY
+func NewPaymentGateway(cfg config.PaymentConfig) (PaymentGateway, error) {
+	switch cfg.Gateway {
+	case "fake":
+		if !cfg.FakeEnabled {
+			return nil, fmt.Errorf("fake payment gateway is not enabled")
+		}
+		return NewFakePaymentGateway(model.PaymentStatusPending, cfg.WebhookSecret), nil
+	case "midtrans":
+		return NewMidtransPaymentGateway(cfg), nil
+	case "stripe":
+		return NewStripePaymentGateway(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown payment gateway: %s", cfg.Gateway)
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"crypto/sha512"
+	"edu-learn/config"
+	"edu-learn/model"
+	"edu-learn/utils/apperror"
+	modelutils "edu-learn/utils/model_utils"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func midtransNotification(serverKey string, transactionStatus string, fraudStatus string) []byte {
+	orderID, statusCode, grossAmount := "EDU-1", "200", "150000.00"
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+
+	body, _ := json.Marshal(map[string]string{
+		"order_id":           orderID,
+		"transaction_id":     "9aed5972-5b6a-401e-894b-a32c91ed1a3a",
+		"status_code":        statusCode,
+		"gross_amount":       grossAmount,
+		"signature_key":      hex.EncodeToString(sum[:]),
+		"transaction_status": transactionStatus,
+		"fraud_status":       fraudStatus,
+	})
+	return body
+}
+
+func TestMidtransParseWebhook(t *testing.T) {
+	gateway := NewMidtransPaymentGateway(config.PaymentConfig{ServerKey: "SB-Mid-server-test"})
+
+	tests := []struct {
+		name       string
+		body       []byte
+		wantStatus string
+		wantErr    error
+	}{
+		{"settlement", midtransNotification("SB-Mid-server-test", "settlement", ""), model.PaymentStatusCompleted, nil},
+		{"capture accepted", midtransNotification("SB-Mid-server-test", "capture", "accept"), model.PaymentStatusCompleted, nil},
+		{"capture challenged", midtransNotification("SB-Mid-server-test", "capture", "challenge"), model.PaymentStatusPending, nil},
+		{"pending", midtransNotification("SB-Mid-server-test", "pending", ""), model.PaymentStatusPending, nil},
+		{"expire", midtransNotification("SB-Mid-server-test", "expire", ""), model.PaymentStatusFailed, nil},
+		{"refund", midtransNotification("SB-Mid-server-test", "refund", ""), model.PaymentStatusRefunded, nil},
+		{"partial refund is ignored", midtransNotification("SB-Mid-server-test", "partial_refund", ""), "", ErrWebhookIgnored},
+		{"wrong server key", midtransNotification("another-key", "settlement", ""), "", apperror.ErrUnauthorized},
+		{"invalid json", []byte("{"), "", apperror.ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := gateway.ParseWebhook(http.Header{}, tt.body)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseWebhook error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWebhook: %v", err)
+			}
+			if event.Reference != "EDU-1" || event.Status != tt.wantStatus || event.EventID == "" {
+				t.Errorf("event = %+v, want reference EDU-1 and status %s", event, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMidtransParseWebhookEventID(t *testing.T) {
+	gateway := NewMidtransPaymentGateway(config.PaymentConfig{ServerKey: "key"})
+
+	// Notifikasi ulang untuk status yang sama harus punya id yang sama, status lain harus beda
+	first, _ := gateway.ParseWebhook(http.Header{}, midtransNotification("key", "capture", "challenge"))
+	again, _ := gateway.ParseWebhook(http.Header{}, midtransNotification("key", "capture", "challenge"))
+	accepted, _ := gateway.ParseWebhook(http.Header{}, midtransNotification("key", "capture", "accept"))
+
+	if first.EventID != again.EventID {
+		t.Errorf("redelivery event id %q != %q", again.EventID, first.EventID)
+	}
+	if first.EventID == accepted.EventID {
+		t.Errorf("different transition reused event id %q", first.EventID)
+	}
+}
+
+func stripeEvent(eventType string, object map[string]interface{}) []byte {
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":   "evt_1",
+		"type": eventType,
+		"data": map[string]interface{}{"object": object},
+	})
+	return body
+}
+
+func stripeHeader(secret []byte, body []byte, signedAt time.Time) http.Header {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	header := http.Header{}
+	header.Set(StripeSignatureHeader, fmt.Sprintf("t=%s,v1=%s", timestamp, SignWebhookPayload(secret, []byte(timestamp+"."+string(body)))))
+	return header
+}
+
+func TestStripeParseWebhook(t *testing.T) {
+	secret := []byte("whsec_test")
+	gateway := NewStripePaymentGateway(config.PaymentConfig{WebhookSecret: secret})
+
+	tests := []struct {
+		name       string
+		body       []byte
+		wantStatus string
+		wantErr    error
+	}{
+		{"payment intent succeeded", stripeEvent("payment_intent.succeeded", map[string]interface{}{"id": "pi_1"}), model.PaymentStatusCompleted, nil},
+		{"payment intent canceled", stripeEvent("payment_intent.canceled", map[string]interface{}{"id": "pi_1"}), model.PaymentStatusFailed, nil},
+		{"checkout session paid", stripeEvent("checkout.session.completed", map[string]interface{}{"id": "cs_1", "payment_intent": "pi_1", "payment_status": "paid"}), model.PaymentStatusCompleted, nil},
+		{"checkout session unpaid is ignored", stripeEvent("checkout.session.completed", map[string]interface{}{"id": "cs_1", "payment_intent": "pi_1", "payment_status": "unpaid"}), "", ErrWebhookIgnored},
+		{"checkout async payment succeeded", stripeEvent("checkout.session.async_payment_succeeded", map[string]interface{}{"id": "cs_1", "payment_intent": "pi_1"}), model.PaymentStatusCompleted, nil},
+		{"checkout session expired", stripeEvent("checkout.session.expired", map[string]interface{}{"id": "cs_1", "payment_intent": "pi_1"}), model.PaymentStatusFailed, nil},
+		{"charge fully refunded", stripeEvent("charge.refunded", map[string]interface{}{"id": "ch_1", "payment_intent": "pi_1", "refunded": true}), model.PaymentStatusRefunded, nil},
+		{"charge partially refunded is ignored", stripeEvent("charge.refunded", map[string]interface{}{"id": "ch_1", "payment_intent": "pi_1", "refunded": false}), "", ErrWebhookIgnored},
+		{"payment attempt failed is ignored", stripeEvent("payment_intent.payment_failed", map[string]interface{}{"id": "pi_1"}), "", ErrWebhookIgnored},
+		{"unrelated event is ignored", stripeEvent("customer.created", map[string]interface{}{"id": "cus_1"}), "", ErrWebhookIgnored},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := gateway.ParseWebhook(stripeHeader(secret, tt.body, time.Now()), tt.body)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseWebhook error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWebhook: %v", err)
+			}
+			if event != (modelutils.PaymentWebhookEvent{EventID: "evt_1", Reference: "pi_1", Status: tt.wantStatus}) {
+				t.Errorf("event = %+v, want evt_1/pi_1/%s", event, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestStripeParseWebhookRejectsBadSignature(t *testing.T) {
+	secret := []byte("whsec_test")
+	gateway := NewStripePaymentGateway(config.PaymentConfig{WebhookSecret: secret})
+	body := stripeEvent("payment_intent.succeeded", map[string]interface{}{"id": "pi_1"})
+
+	tests := []struct {
+		name   string
+		header http.Header
+	}{
+		{"missing header", http.Header{}},
+		{"wrong secret", stripeHeader([]byte("whsec_other"), body, time.Now())},
+		{"replayed old event", stripeHeader(secret, body, time.Now().Add(-10*time.Minute))},
+		{"signed other body", stripeHeader(secret, []byte(`{"id":"evt_2"}`), time.Now())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := gateway.ParseWebhook(tt.header, body)
+			if !errors.Is(err, apperror.ErrUnauthorized) {
+				t.Errorf("ParseWebhook error = %v, want unauthorized", err)
+			}
+		})
+	}
+}
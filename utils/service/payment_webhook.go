@@ -0,0 +1,115 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header yang berisi signature HMAC-SHA256 dari body webhook
+const WebhookSignatureHeader = "X-Signature"
+
+// StripeSignatureHeader => Format "t=<unix>,v1=<hex>", HMAC-SHA256 dari "<t>.<body>"
+const StripeSignatureHeader = "Stripe-Signature"
+
+// stripeSignatureTolerance => Event dengan timestamp lebih lama dari ini ditolak supaya tidak bisa diputar ulang
+const stripeSignatureTolerance = 5 * time.Minute
+
+// ErrWebhookIgnored => Notifikasi sah tapi tidak mengubah status pembayaran, tetap dibalas 200 supaya tidak dikirim ulang
+var ErrWebhookIgnored = errors.New("webhook event ignored")
+
+// SignWebhookPayload => Hitung signature HMAC-SHA256 (hex) dari body webhook
+func SignWebhookPayload(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature => Bandingkan signature dengan constant-time compare
+func VerifyWebhookSignature(secret, payload []byte, signature string) bool {
+	if len(secret) == 0 || signature == "" {
+		return false
+	}
+
+	expected := SignWebhookPayload(secret, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// verifyStripeSignature => Salah satu v1 harus cocok dan timestamp masih dalam toleransi
+func verifyStripeSignature(secret []byte, header string, payload []byte, now time.Time) bool {
+	if len(secret) == 0 || header == "" {
+		return false
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := now.Sub(time.Unix(unix, 0))
+	if age > stripeSignatureTolerance || age < -stripeSignatureTolerance {
+		return false
+	}
+
+	expected := SignWebhookPayload(secret, []byte(timestamp+"."+string(payload)))
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type fakeWebhookSender struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// FakeWebhookSender => Simulasi payment gateway yang mengirim webhook bertanda tangan
+type FakeWebhookSender interface {
+	Send(eventID, reference, status string) (*http.Response, error)
+}
+
+func (f *fakeWebhookSender) Send(eventID, reference, status string) (*http.Response, error) {
+	body, err := json.Marshal(map[string]string{
+		"event_id":  eventID,
+		"reference": reference,
+		"status":    status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, f.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(f.secret, body))
+
+	return f.client.Do(req)
+}
+
+func NewFakeWebhookSender(url string, secret []byte) FakeWebhookSender {
+	return &fakeWebhookSender{url: url, secret: secret, client: &http.Client{Timeout: 10 * time.Second}}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"edu-learn/config"
+	"edu-learn/model"
+	"edu-learn/repository"
+	modelutils "edu-learn/utils/model_utils"
+	"sync"
+	"time"
+)
+
+// Token yang sudah pasti dicabut disimpan di memory supaya tidak perlu query lagi. Token yang belum
+// dicabut tetap dicek ke Postgres setiap request (sesi, jti, lalu user), karena bisa saja dicabut
+// oleh instance lain atau sebelum restart
+type tokenRevocationService struct {
+	repo             repository.TokenRevocationRepository
+	repoRefreshToken repository.RefreshTokenRepository
+	repoSession      repository.SessionRepository
+
+	accessTokenLifeTime  time.Duration // umur access token terlama, termasuk token impersonation
+	refreshTokenLifeTime time.Duration
+
+	mu       sync.RWMutex
+	tokens   map[string]time.Time   // jti => waktu kedaluwarsa token
+	users    map[int]userRevocation // user id => waktu pencabutan semua token
+	sessions map[string]time.Time   // session id yang sudah dicabut => waktu entri boleh dihapus
+}
+
+// userRevocation => Setelah expiresAt semua access token yang terbit sebelum revokedAt sudah kedaluwarsa
+type userRevocation struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+type TokenRevocationService interface {
+	RevokeToken(claim modelutils.JWTPayloadClaim) error
+	RevokeUserTokens(userID int) error
+	RevokeSession(sessionID string) error
+	IsRevoked(claim modelutils.JWTPayloadClaim) (bool, error)
+	DeleteExpiredTokens() error
+}
+
+// DeleteExpiredTokens => Token yang sudah kedaluwarsa ditolak oleh validasi exp, daftar pencabutannya tidak perlu disimpan
+func (t *tokenRevocationService) DeleteExpiredTokens() error {
+	err := t.repo.DeleteExpiredTokens()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.deleteExpiredFromMemory(time.Now())
+
+	return nil
+}
+
+func (t *tokenRevocationService) RevokeToken(claim modelutils.JWTPayloadClaim) error {
+	expiresAt := tokenExpiry(claim)
+
+	err := t.repo.RevokeToken(&model.RevokedToken{JTI: claim.ID, UserID: claim.UserId, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.deleteExpiredFromMemory(time.Now())
+	t.tokens[claim.ID] = expiresAt
+
+	return nil
+}
+
+// RevokeUserTokens => Cabut semua access token dan refresh token milik user. iat hanya presisi detik,
+// jadi pencabutan dicatat per detik dan fungsi ini baru kembali setelah detik itu lewat, supaya token
+// baru (misalnya login ulang setelah ganti password) tidak ikut tercabut
+func (t *tokenRevocationService) RevokeUserTokens(userID int) error {
+	now := time.Now().Truncate(time.Second)
+
+	err := t.repo.RevokeUserTokens(userID, now)
+	if err != nil {
+		return err
+	}
+
+	err = t.repoRefreshToken.RevokeUserTokens(userID)
+	if err != nil {
+		return err
+	}
+
+	err = t.repoSession.RevokeUserSessions(userID)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.users[userID] = t.newUserRevocation(now)
+	t.mu.Unlock()
+
+	time.Sleep(time.Until(now.Add(time.Second)))
+
+	return nil
+}
+
+// RevokeSession => Cabut satu sesi beserta access token (lewat sid) dan refresh token-nya
+func (t *tokenRevocationService) RevokeSession(sessionID string) error {
+	err := t.repoSession.RevokeSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.sessions[sessionID] = time.Now().Add(t.refreshTokenLifeTime)
+	t.mu.Unlock()
+
+	return nil
+}
+
+func (t *tokenRevocationService) IsRevoked(claim modelutils.JWTPayloadClaim) (bool, error) {
+	// Token tanpa jti diterbitkan sebelum fitur revocation ada
+	if claim.ID == "" || claim.IssuedAt == nil {
+		return true, nil
+	}
+
+	t.mu.RLock()
+	_, tokenRevoked := t.tokens[claim.ID]
+	userRevocation, userRevoked := t.users[claim.UserId]
+	_, sessionRevoked := t.sessions[claim.SessionID]
+	t.mu.RUnlock()
+
+	if tokenRevoked || sessionRevoked || (userRevoked && issuedBefore(claim, userRevocation.revokedAt)) {
+		return true, nil
+	}
+
+	if claim.SessionID != "" {
+		sessionRevoked, err := t.repoSession.IsSessionRevoked(claim.SessionID)
+		if err != nil {
+			return true, err
+		}
+		if sessionRevoked {
+			t.mu.Lock()
+			t.sessions[claim.SessionID] = time.Now().Add(t.refreshTokenLifeTime)
+			t.mu.Unlock()
+			return true, nil
+		}
+	}
+
+	tokenRevoked, err := t.repo.IsTokenRevoked(claim.ID)
+	if err != nil {
+		return true, err
+	}
+	if tokenRevoked {
+		t.mu.Lock()
+		t.tokens[claim.ID] = tokenExpiry(claim)
+		t.mu.Unlock()
+		return true, nil
+	}
+
+	userRevokedAt, err := t.repo.GetUserRevokedAt(claim.UserId)
+	if err != nil {
+		return true, err
+	}
+	if !userRevokedAt.IsZero() {
+		t.mu.Lock()
+		t.users[claim.UserId] = t.newUserRevocation(userRevokedAt)
+		t.mu.Unlock()
+	}
+
+	return issuedBefore(claim, userRevokedAt), nil
+}
+
+// deleteExpiredFromMemory => Dipanggil dengan lock yang sudah dipegang. Entri yang dihapus tetap
+// dicek ke Postgres jika tokennya masih dipakai
+func (t *tokenRevocationService) deleteExpiredFromMemory(now time.Time) {
+	for jti, exp := range t.tokens {
+		if exp.Before(now) {
+			delete(t.tokens, jti)
+		}
+	}
+
+	for userID, revocation := range t.users {
+		if revocation.expiresAt.Before(now) {
+			delete(t.users, userID)
+		}
+	}
+
+	for sessionID, exp := range t.sessions {
+		if exp.Before(now) {
+			delete(t.sessions, sessionID)
+		}
+	}
+}
+
+func (t *tokenRevocationService) newUserRevocation(revokedAt time.Time) userRevocation {
+	return userRevocation{revokedAt: revokedAt, expiresAt: revokedAt.Add(t.accessTokenLifeTime)}
+}
+
+func tokenExpiry(claim modelutils.JWTPayloadClaim) time.Time {
+	if claim.ExpiresAt == nil {
+		return time.Now()
+	}
+	return claim.ExpiresAt.Time
+}
+
+// issuedBefore => iat hanya presisi detik, jadi dibandingkan dengan detik pencabutan. Token yang terbit
+// di detik yang sama ikut dicabut, token baru baru diterbitkan setelah detik itu lewat
+func issuedBefore(claim modelutils.JWTPayloadClaim, revokedAt time.Time) bool {
+	if revokedAt.IsZero() {
+		return false
+	}
+	return !claim.IssuedAt.Time.After(revokedAt.Truncate(time.Second))
+}
+
+func NewTokenRevocationService(repo repository.TokenRevocationRepository, repoRefreshToken repository.RefreshTokenRepository, repoSession repository.SessionRepository, cfg config.TokenConfig) TokenRevocationService {
+	accessTokenLifeTime := cfg.AccessTokenLifeTime
+	if cfg.ImpersonationTokenLifeTime > accessTokenLifeTime {
+		accessTokenLifeTime = cfg.ImpersonationTokenLifeTime
+	}
+
+	return &tokenRevocationService{
+		repo:                 repo,
+		repoRefreshToken:     repoRefreshToken,
+		repoSession:          repoSession,
+		accessTokenLifeTime:  accessTokenLifeTime,
+		refreshTokenLifeTime: cfg.RefreshTokenLifeTime,
+		tokens:               make(map[string]time.Time),
+		users:                make(map[int]userRevocation),
+		sessions:             make(map[string]time.Time),
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"edu-learn/config"
+	"edu-learn/repository"
+	modelutils "edu-learn/utils/model_utils"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type fakeTokenRevocationRepository struct {
+	repository.TokenRevocationRepository
+	userRevokedAt map[int]time.Time
+}
+
+func (f *fakeTokenRevocationRepository) RevokeUserTokens(userID int, revokedAt time.Time) error {
+	f.userRevokedAt[userID] = revokedAt
+	return nil
+}
+
+func (f *fakeTokenRevocationRepository) GetUserRevokedAt(userID int) (time.Time, error) {
+	return f.userRevokedAt[userID], nil
+}
+
+func (f *fakeTokenRevocationRepository) IsTokenRevoked(jti string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeTokenRevocationRepository) DeleteExpiredTokens() error {
+	return nil
+}
+
+type fakeRefreshTokenRepository struct {
+	repository.RefreshTokenRepository
+}
+
+func (fakeRefreshTokenRepository) RevokeUserTokens(userID int) error {
+	return nil
+}
+
+type fakeSessionRepository struct {
+	repository.SessionRepository
+}
+
+func (fakeSessionRepository) RevokeSession(id string) error {
+	return nil
+}
+
+func (fakeSessionRepository) RevokeUserSessions(userID int) error {
+	return nil
+}
+
+func (fakeSessionRepository) IsSessionRevoked(id string) (bool, error) {
+	return false, nil
+}
+
+func newTestRevocationService() (*tokenRevocationService, *fakeTokenRevocationRepository) {
+	repo := &fakeTokenRevocationRepository{userRevokedAt: map[int]time.Time{}}
+	cfg := config.TokenConfig{
+		AccessTokenLifeTime:        15 * time.Minute,
+		ImpersonationTokenLifeTime: 30 * time.Minute,
+		RefreshTokenLifeTime:       24 * time.Hour,
+	}
+	revocation := NewTokenRevocationService(repo, fakeRefreshTokenRepository{}, fakeSessionRepository{}, cfg).(*tokenRevocationService)
+	return revocation, repo
+}
+
+func claimIssuedAt(jti string, userID int, issuedAt time.Time) modelutils.JWTPayloadClaim {
+	claim := modelutils.JWTPayloadClaim{UserId: userID}
+	claim.ID = jti
+	claim.IssuedAt = jwt.NewNumericDate(issuedAt)
+	claim.ExpiresAt = jwt.NewNumericDate(issuedAt.Add(15 * time.Minute))
+	return claim
+}
+
+func TestRevokeUserTokensSecondResolution(t *testing.T) {
+	revocation, repo := newTestRevocationService()
+
+	before := time.Now()
+	err := revocation.RevokeUserTokens(7)
+	if err != nil {
+		t.Fatalf("RevokeUserTokens: %v", err)
+	}
+	revokedAt := repo.userRevokedAt[7]
+
+	if !revokedAt.Equal(revokedAt.Truncate(time.Second)) {
+		t.Errorf("revoked at %v is not truncated to the second", revokedAt)
+	}
+
+	// Token baru diterbitkan setelah RevokeUserTokens selesai, seperti login ulang setelah ganti password
+	issuedAfter := claimIssuedAt("jti-new", 7, time.Now())
+	if !issuedAfter.IssuedAt.Time.After(revokedAt) {
+		t.Fatalf("RevokeUserTokens returned within the revocation second %v", revokedAt)
+	}
+
+	tests := []struct {
+		name        string
+		claim       modelutils.JWTPayloadClaim
+		wantRevoked bool
+	}{
+		{"issued before revocation", claimIssuedAt("jti-old", 7, before.Add(-time.Minute)), true},
+		{"issued in the revocation second", claimIssuedAt("jti-same", 7, revokedAt), true},
+		{"issued after revocation returned", issuedAfter, false},
+		{"other user", claimIssuedAt("jti-other", 8, before.Add(-time.Minute)), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := revocation.IsRevoked(tt.claim)
+			if err != nil {
+				t.Fatalf("IsRevoked: %v", err)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("IsRevoked = %v, want %v", revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestDeleteExpiredFromMemory(t *testing.T) {
+	revocation, _ := newTestRevocationService()
+	now := time.Now()
+
+	revocation.tokens["jti-expired"] = now.Add(-time.Minute)
+	revocation.tokens["jti-active"] = now.Add(time.Minute)
+	revocation.users[1] = revocation.newUserRevocation(now.Add(-31 * time.Minute))
+	revocation.users[2] = revocation.newUserRevocation(now.Add(-20 * time.Minute)) // token impersonation masih berlaku
+	revocation.sessions["session-expired"] = now.Add(-time.Minute)
+	revocation.sessions["session-active"] = now.Add(time.Hour)
+
+	revocation.deleteExpiredFromMemory(now)
+
+	if _, ok := revocation.tokens["jti-expired"]; ok {
+		t.Error("expired token was not pruned")
+	}
+	if _, ok := revocation.tokens["jti-active"]; !ok {
+		t.Error("active token was pruned")
+	}
+	if _, ok := revocation.users[1]; ok {
+		t.Error("user revocation past the access token lifetime was not pruned")
+	}
+	if _, ok := revocation.users[2]; !ok {
+		t.Error("user revocation within the impersonation token lifetime was pruned")
+	}
+	if _, ok := revocation.sessions["session-expired"]; ok {
+		t.Error("expired session was not pruned")
+	}
+	if _, ok := revocation.sessions["session-active"]; !ok {
+		t.Error("active session was pruned")
+	}
+}
+
+func TestRevokeSessionExpiresWithRefreshToken(t *testing.T) {
+	revocation, _ := newTestRevocationService()
+
+	err := revocation.RevokeSession("session-1")
+	if err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+
+	revocation.deleteExpiredFromMemory(time.Now().Add(23 * time.Hour))
+	if _, ok := revocation.sessions["session-1"]; !ok {
+		t.Fatal("session revocation pruned before the refresh token lifetime")
+	}
+
+	revocation.deleteExpiredFromMemory(time.Now().Add(25 * time.Hour))
+	if _, ok := revocation.sessions["session-1"]; ok {
+		t.Error("session revocation kept past the refresh token lifetime")
+	}
+}
@@ -0,0 +1,179 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CourseCategories => Kategori kursus yang boleh dipakai (tag course_category)
+var CourseCategories = []string{"Programming", "Database", "Data Science", "Design", "Business", "Marketing", "Language", "Other"}
+
+// URLSchemes => Skema URL yang boleh dipakai untuk link file materi (tag http_url)
+var URLSchemes = []string{"http", "https"}
+
+// MaxPrice => Batas kolom decimal(10,2)
+const MaxPrice = 99999999.99
+
+// FieldError => Satu field yang gagal validasi, code dipakai client untuk menampilkan pesan sendiri
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Register => Daftarkan aturan custom dan pakai nama field dari tag json di pesan error
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	rules := map[string]validator.Func{
+		"notblank":        notBlank,
+		"price":           price,
+		"course_category": courseCategory,
+		"http_url":        httpURL,
+	}
+	for tag, rule := range rules {
+		err := v.RegisterValidation(tag, rule)
+		if err != nil {
+			return fmt.Errorf("failed to register validation %s: %v", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// FieldErrors => Ubah error binding menjadi daftar field, false jika bukan error validasi (misalnya JSON rusak)
+func FieldErrors(err error) ([]FieldError, bool) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fieldError := range validationErrors {
+			fields = append(fields, toFieldError(fieldError))
+		}
+		return fields, true
+	}
+
+	// Tipe data salah (misalnya price berisi string) juga dianggap error field
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		return []FieldError{{
+			Field:   typeError.Field,
+			Code:    "invalid_type",
+			Message: fmt.Sprintf("%s must be of type %s", typeError.Field, typeError.Type.String()),
+		}}, true
+	}
+
+	return nil, false
+}
+
+func toFieldError(fieldError validator.FieldError) FieldError {
+	field := fieldError.Field()
+	result := FieldError{Field: field, Code: fieldError.Tag()}
+
+	switch fieldError.Tag() {
+	case "required", "notblank":
+		result.Code = "required"
+		result.Message = fmt.Sprintf("%s is required", field)
+	case "email":
+		result.Code = "invalid_email"
+		result.Message = fmt.Sprintf("%s must be a valid email address", field)
+	case "min", "gte":
+		result.Code, result.Message = boundError(fieldError, "too_short", "too_small", "at least")
+	case "gt":
+		result.Code, result.Message = boundError(fieldError, "too_short", "too_small", "more than")
+	case "max", "lte":
+		result.Code, result.Message = boundError(fieldError, "too_long", "too_large", "at most")
+	case "lt":
+		result.Code, result.Message = boundError(fieldError, "too_long", "too_large", "less than")
+	case "oneof":
+		result.Code = "invalid_value"
+		result.Message = fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fieldError.Param(), " ", ", "))
+	case "price":
+		result.Code = "invalid_price"
+		result.Message = fmt.Sprintf("%s must be between 0 and %.2f with at most 2 decimal places", field, MaxPrice)
+	case "course_category":
+		result.Code = "invalid_category"
+		result.Message = fmt.Sprintf("%s must be one of: %s", field, strings.Join(CourseCategories, ", "))
+	case "http_url":
+		result.Code = "invalid_url"
+		result.Message = fmt.Sprintf("%s must be a valid %s URL", field, strings.Join(URLSchemes, " or "))
+	default:
+		result.Message = fmt.Sprintf("%s is invalid", field)
+	}
+
+	return result
+}
+
+// boundError => Pesan min/max tergantung jenis field: panjang teks, jumlah item, atau nilai angka
+func boundError(fieldError validator.FieldError, lengthCode string, valueCode string, bound string) (string, string) {
+	field, param := fieldError.Field(), fieldError.Param()
+
+	switch fieldError.Kind() {
+	case reflect.String:
+		return lengthCode, fmt.Sprintf("%s must be %s %s characters", field, bound, param)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return lengthCode, fmt.Sprintf("%s must contain %s %s items", field, bound, param)
+	}
+
+	return valueCode, fmt.Sprintf("%s must be %s %s", field, bound, param)
+}
+
+func notBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// price => Tidak negatif, maksimal 2 angka di belakang koma, dan muat di kolom decimal(10,2)
+func price(fl validator.FieldLevel) bool {
+	var value float64
+	switch fl.Field().Kind() {
+	case reflect.Float32, reflect.Float64:
+		value = fl.Field().Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(fl.Field().Int())
+	default:
+		return false
+	}
+
+	if value < 0 || value > MaxPrice || math.IsNaN(value) {
+		return false
+	}
+
+	cents := value * 100
+	return math.Abs(cents-math.Round(cents)) < 1e-6
+}
+
+func courseCategory(fl validator.FieldLevel) bool {
+	category := fl.Field().String()
+	for _, allowed := range CourseCategories {
+		if category == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func httpURL(fl validator.FieldLevel) bool {
+	parsed, err := url.Parse(fl.Field().String())
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+
+	for _, scheme := range URLSchemes {
+		if strings.EqualFold(parsed.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}